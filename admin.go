@@ -2,6 +2,7 @@ package ghost
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -29,7 +31,24 @@ type AdminClient struct {
 	Database       *AdminDatabaseService
 	Posts          *AdminPostsService
 	Redirects      *AdminRedirectsService
+	Routes         *AdminRoutesService
 	Session        *AdminSessionService
+	Themes         *AdminThemesService
+
+	// apiLimiter and loginLimiter, when set, throttle regular API calls and
+	// session creation respectively.
+	apiLimiter   *rateLimiter
+	loginLimiter *rateLimiter
+
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+
+	// redirectLint, when set, makes Redirects.Upload refuse redirects that
+	// fail linting.
+	redirectLint *LintOptions
+
+	// ctx, when set, is the context of the requests made, see WithContext.
+	ctx context.Context
 
 	// Reuse a single struct instead of allocating one for each service on the heap.
 	common adminService
@@ -39,12 +58,48 @@ type adminService struct {
 	client *AdminClient
 }
 
+// AdminClientOption configures optional behavior of an AdminClient.
+type AdminClientOption func(*AdminClient)
+
+// WithRateLimit throttles API calls made through the client, across all of its
+// services, to rate requests per second with bursts of up to burst requests.
+// A rate of zero or less means no limit. Session creation is budgeted
+// separately, see WithLoginRateLimit. Waiting for a turn gives up once the
+// request's context is done; service calls take theirs from WithContext.
+func WithRateLimit(rate float64, burst int) AdminClientOption {
+	return func(c *AdminClient) {
+		c.apiLimiter = newRateLimiter(rate, burst)
+	}
+}
+
+// WithLoginRateLimit throttles session creation to rate attempts per second
+// with bursts of up to burst attempts, keeping clear of Ghost's brute-force
+// protection on the login endpoint. A rate of zero or less means no limit.
+func WithLoginRateLimit(rate float64, burst int) AdminClientOption {
+	return func(c *AdminClient) {
+		c.loginLimiter = newRateLimiter(rate, burst)
+	}
+}
+
+// WithRedirectLint makes Redirects.Upload lint the redirects with opts first,
+// returning a *LintError without uploading anything if any problems are
+// found. See LintRedirects.
+func WithRedirectLint(opts *LintOptions) AdminClientOption {
+	return func(c *AdminClient) {
+		if opts == nil {
+			opts = &LintOptions{}
+		}
+		c.redirectLint = opts
+	}
+}
+
 // NewAdminClient returns a new client for interacting with Ghost Admin endpoints.
 // baseURL should be the base admin url of the intance, in most cases taking the form
 // of e.g., https://blah.pubbit.io with no trailing slash. It may additionally
 // contain the subpath, but that too must omit the trailing slash.
-// httpClient should handle authentication itself
-func NewAdminClient(baseURL string, httpClient *http.Client) (*AdminClient, error) {
+// httpClient should handle authentication itself. Options, if any, are applied
+// in order.
+func NewAdminClient(baseURL string, httpClient *http.Client, opts ...AdminClientOption) (*AdminClient, error) {
 	burl, err := parseBaseURL(baseURL)
 	if err != nil {
 		return nil, err
@@ -54,13 +109,45 @@ func NewAdminClient(baseURL string, httpClient *http.Client) (*AdminClient, erro
 	burl.Path += BaseAdminPath
 
 	c := &AdminClient{client: httpClient, BaseURL: burl, UserAgent: "go-ghost"}
+	c.initServices()
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *AdminClient) initServices() {
 	c.common.client = c
 	c.Authentication = (*AdminAuthenticationService)(&c.common)
 	c.Database = (*AdminDatabaseService)(&c.common)
 	c.Posts = (*AdminPostsService)(&c.common)
 	c.Redirects = (*AdminRedirectsService)(&c.common)
+	c.Routes = (*AdminRoutesService)(&c.common)
 	c.Session = (*AdminSessionService)(&c.common)
-	return c, nil
+	c.Themes = (*AdminThemesService)(&c.common)
+}
+
+// WithContext returns a shallow copy of c whose requests, including those of
+// its services, are made with ctx: they are cancelled, and give up waiting
+// for a rate limit, once ctx is done. The copy shares the rate limits and
+// hooks of c.
+func (c *AdminClient) WithContext(ctx context.Context) *AdminClient {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c2 := new(AdminClient)
+	*c2 = *c
+	c2.ctx = ctx
+	c2.initServices()
+	return c2
+}
+
+// withContext sets the context of req to that of the client, if any.
+func (c *AdminClient) withContext(req *http.Request) *http.Request {
+	if c.ctx == nil {
+		return req
+	}
+	return req.WithContext(c.ctx)
 }
 
 func parseBaseURL(baseURL string) (*url.URL, error) {
@@ -112,7 +199,7 @@ func (c *AdminClient) NewRequest(method, urlStr string, body interface{}) (*http
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
-	return req, nil
+	return c.withContext(req), nil
 }
 
 var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -165,28 +252,93 @@ func (c *AdminClient) NewUploadRequest(urlStr string, writePart WriteFilePart, p
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
 	req.Header.Set("Content-Type", mp.FormDataContentType())
-	return req, nil
+	return c.withContext(req), nil
+}
+
+// NewStreamingUploadRequest is like NewUploadRequest, except the multipart
+// payload is produced by writePart as the request body is read, rather than
+// buffered in memory up front. This suits large uploads. Errors from writePart
+// surface as errors sending the request. The request must be sent, or its
+// body closed, to release the writing goroutine.
+func (c *AdminClient) NewStreamingUploadRequest(urlStr string, writePart WriteFilePart, params map[string]string) (*http.Request, error) {
+	if !strings.HasSuffix(c.BaseURL.Path, "/") {
+		return nil, fmt.Errorf("BaseURL must have a trailing slash, but %q does not", c.BaseURL)
+	}
+	u, err := c.BaseURL.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	mp := multipart.NewWriter(pw)
+	req, err := http.NewRequest("POST", u.String(), pr)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := writePart(mp)
+		for name, value := range params {
+			if err != nil {
+				break
+			}
+			err = mp.WriteField(name, value)
+		}
+		if err == nil {
+			err = mp.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	req.Header.Set("Content-Type", mp.FormDataContentType())
+	return c.withContext(req), nil
 }
 
 // Do sends an API request and returns the API response. The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred. If v implements the io.Writer
 // interface, the raw response body will be written to v, without attempting to
-// first decode it.
+// first decode it. For non-2xx statuses the response is returned along with
+// an *ErrorResponse. If the client is rate limited, Do first waits for its turn,
+// giving up once the request's context is done.
 func (c *AdminClient) Do(req *http.Request, v interface{}) (*http.Response, error) {
+	limiter := c.apiLimiter
+	if isLoginBudget(req.Context()) {
+		limiter = c.loginLimiter
+	}
+	if err := limiter.Wait(req.Context()); err != nil {
+		// like http.Client.Do, always close the body, releasing any
+		// streaming upload.
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	c.beforeRequest(req)
+	start := time.Now()
+	resp, err := c.do(req, v)
+	c.afterResponse(req, resp, time.Since(start), err)
+	return resp, err
+}
+
+func (c *AdminClient) do(req *http.Request, v interface{}) (*http.Response, error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("received %v status from API", resp.StatusCode)
+	if err := checkResponse(resp); err != nil {
+		return resp, err
 	}
 
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
-			io.Copy(w, resp.Body)
+			_, err = io.Copy(w, resp.Body)
 		} else {
 			decErr := json.NewDecoder(resp.Body).Decode(v)
 			if decErr == io.EOF {
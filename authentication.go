@@ -21,21 +21,21 @@ type setupWrapper struct {
 }
 
 // Setup initializes the Ghost instance.
-func (s *AdminAuthenticationService) Setup(details *SetupDetails) error {
+func (s *AdminAuthenticationService) Setup(details *SetupDetails) (*Response, error) {
 	wrapper := &setupWrapper{
 		Setup: []*SetupDetails{details},
 	}
 	req, err := s.client.NewRequest("POST", "authentication/setup", wrapper)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	response, err := s.client.Do(req, nil)
 	if err != nil {
-		return err
+		return newResponse(response), err
 	}
 	if response.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to setup")
+		return newResponse(response), fmt.Errorf("failed to setup")
 	}
-	return nil
+	return newResponse(response), nil
 }
@@ -0,0 +1,428 @@
+// Package backup takes and restores archives of Ghost sites, holding the
+// database export, redirects, routes, active theme and content images, and
+// rotates them according to a retention policy.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// Entry names within an archive.
+const (
+	ManifestEntry  = "manifest.json"
+	DatabaseEntry  = "db.json"
+	RedirectsEntry = "redirects.json"
+	// RedirectsYAMLEntry replaces RedirectsEntry for sites whose redirects
+	// are in the redirects.yaml format of Ghost 4 and later.
+	RedirectsYAMLEntry = "redirects.yaml"
+	RoutesEntry        = "routes.yaml"
+	ThemeDir           = "themes/"
+	ImagesDir          = "images/"
+)
+
+// Options configure what a backup includes.
+type Options struct {
+	// SkipTheme leaves the active theme out of the archive.
+	SkipTheme bool
+	// SkipImages leaves content images out of the archive.
+	SkipImages bool
+	// HTTPClient fetches content images, which are public. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	Site      string    `json:"site"`
+	CreatedAt time.Time `json:"created_at"`
+	Version   string    `json:"version,omitempty"`
+	Theme     string    `json:"theme,omitempty"`
+	Images    int       `json:"images"`
+	// MissingImages are images referenced by content which could not be
+	// downloaded, e.g. because they were deleted.
+	MissingImages []string `json:"missing_images,omitempty"`
+}
+
+// Backup takes an archive of the site and stores it under ArchiveName(site,
+// now), returning the manifest of what was archived. Cancelling ctx stops
+// the backup, including any transfer in progress.
+func Backup(ctx context.Context, client *ghost.AdminClient, site string, storage Storage, opts *Options) (*Manifest, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	client = client.WithContext(ctx)
+	manifest := &Manifest{Site: site, CreatedAt: time.Now().UTC()}
+
+	// Put replaces existing archives, so an archive of the same name taken
+	// concurrently is not overwritten.
+	name := ArchiveName(site, manifest.CreatedAt)
+	names, err := storage.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range names {
+		if existing == name {
+			return nil, fmt.Errorf("archive %s already exists", name)
+		}
+	}
+
+	tmpDir, err := ioutil.TempDir("", "ghost-backup")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// entries are staged on disk first, as tar needs their sizes up front.
+	var entries []*stagedEntry
+	stage := func(name string, write func(w io.Writer) error) error {
+		local := filepath.Join(tmpDir, strconv.Itoa(len(entries)))
+		f, err := os.Create(local)
+		if err != nil {
+			return err
+		}
+		if err := write(f); err != nil {
+			f.Close()
+			if err == errImageMissing {
+				return err
+			}
+			return fmt.Errorf("failed to back up %s: %v", name, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		entries = append(entries, &stagedEntry{name: name, local: local})
+		return nil
+	}
+
+	err = stage(DatabaseEntry, func(w io.Writer) error {
+		_, _, err := client.Database.ExportTo(w, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the redirects file is kept as is, in its own format, which decides the
+	// name of its entry.
+	redirects := &bytes.Buffer{}
+	format, _, err := client.Redirects.DownloadRaw(redirects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up redirects: %v", err)
+	}
+	redirectsEntry := RedirectsEntry
+	if format == ghost.RedirectsYAML {
+		redirectsEntry = RedirectsYAMLEntry
+	}
+	err = stage(redirectsEntry, func(w io.Writer) error {
+		_, err := redirects.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = stage(RoutesEntry, func(w io.Writer) error {
+		_, err := client.Routes.DownloadFile(w)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.SkipTheme {
+		themes, _, err := client.Themes.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, theme := range themes {
+			if theme.Active == nil || !*theme.Active || theme.Name == nil {
+				continue
+			}
+			manifest.Theme = *theme.Name
+			err = stage(ThemeDir+*theme.Name+".zip", func(w io.Writer) error {
+				_, err := client.Themes.Download(*theme.Name, w)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	exportFile := entries[0].local
+	meta, err := readExportMeta(exportFile)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		manifest.Version = meta.Version
+	}
+
+	if !opts.SkipImages {
+		httpClient := opts.HTTPClient
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		images, err := scanImages(exportFile, siteURL(client))
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			name := ImagesDir + image.path
+			err := stage(name, func(w io.Writer) error {
+				return download(ctx, httpClient, image.url, w)
+			})
+			if err == errImageMissing {
+				manifest.MissingImages = append(manifest.MissingImages, image.url)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			manifest.Images++
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeArchive(pw, manifest, entries))
+	}()
+	if err := storage.Put(name, pr); err != nil {
+		pr.CloseWithError(err)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// stagedEntry is an archive entry staged in a local file.
+type stagedEntry struct {
+	name  string
+	local string
+}
+
+func writeArchive(w io.Writer, manifest *Manifest, entries []*stagedEntry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    ManifestEntry,
+		Mode:    0644,
+		Size:    int64(len(b)),
+		ModTime: manifest.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := addFile(tw, entry.name, entry.local, manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addFile(tw *tar.Writer, name, local string, modTime time.Time) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    info.Size(),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// readExportMeta reads the meta of the staged database export, without
+// decoding the data. Ghost writes the meta ahead of the data.
+func readExportMeta(local string) (*ghost.DatabaseMeta, error) {
+	f, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for _, want := range []interface{}{json.Delim('{'), "db", json.Delim('['), json.Delim('{')} {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read database export: %v", err)
+		}
+		if tok != want {
+			return nil, fmt.Errorf("failed to read database export: unexpected %v", tok)
+		}
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read database export: %v", err)
+		}
+		if key == "meta" {
+			meta := new(ghost.DatabaseMeta)
+			if err := dec.Decode(meta); err != nil {
+				return nil, fmt.Errorf("failed to read database export: %v", err)
+			}
+			return meta, nil
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, fmt.Errorf("failed to read database export: %v", err)
+		}
+	}
+	return nil, nil
+}
+
+// siteURL is the root url of the site, i.e. the client's base url without
+// the admin api path.
+func siteURL(client *ghost.AdminClient) *url.URL {
+	u := *client.BaseURL
+	u.Path = strings.TrimSuffix(u.Path, ghost.BaseAdminPath) + "/"
+	return &u
+}
+
+var imageRef = regexp.MustCompile(`(?:__GHOST_URL__|https?://[^"'\s()<>\\]+)?/content/images/[^"'\s()<>\\?#]+`)
+
+// maxImageRefLen bounds the length of image references, so that the export
+// can be scanned in chunks without missing references spanning two chunks.
+const maxImageRefLen = 4096
+
+type imageRefURL struct {
+	url  string
+	path string
+}
+
+// scanImages finds the content images of the site referenced anywhere in the
+// staged export, be it by absolute url, relative url or Ghost's url
+// placeholder. Images hosted elsewhere are ignored. The export is scanned in
+// chunks, as it may be too large to hold in memory.
+func scanImages(local string, site *url.URL) ([]*imageRefURL, error) {
+	f, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := make(map[string]bool)
+	var images []*imageRefURL
+	add := func(ref string) {
+		ref = strings.TrimPrefix(ref, "__GHOST_URL__")
+		u, err := site.Parse(ref)
+		if err != nil || u.Host != site.Host {
+			return
+		}
+
+		i := strings.Index(u.Path, "/content/images/")
+		p := path.Clean(u.Path[i+len("/content/images/"):])
+		if seen[p] || strings.HasPrefix(p, "..") {
+			return
+		}
+		seen[p] = true
+		images = append(images, &imageRefURL{url: u.String(), path: p})
+	}
+
+	chunk := make([]byte, 1<<20)
+	var buf []byte
+	for {
+		n, readErr := io.ReadFull(f, chunk)
+		buf = append(buf, chunk[:n]...)
+		eof := readErr == io.EOF || readErr == io.ErrUnexpectedEOF
+		if readErr != nil && !eof {
+			return nil, readErr
+		}
+
+		// references near the end of the buffer may continue in the next
+		// chunk, so they are carried over and matched again.
+		carry := len(buf)
+		if !eof {
+			carry = len(buf) - maxImageRefLen
+			if carry < 0 {
+				carry = 0
+			}
+		}
+		for _, loc := range imageRef.FindAllIndex(buf, -1) {
+			if loc[1] > carry && !eof {
+				if loc[0] < carry {
+					carry = loc[0]
+				}
+				break
+			}
+			add(string(buf[loc[0]:loc[1]]))
+		}
+		if eof {
+			break
+		}
+		buf = append([]byte(nil), buf[carry:]...)
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].path < images[j].path
+	})
+	return images, nil
+}
+
+var errImageMissing = fmt.Errorf("image missing")
+
+func download(ctx context.Context, client *http.Client, u string, w io.Writer) error {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errImageMissing
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("received %v status fetching %s", resp.StatusCode, u)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,217 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/stretchr/testify/require"
+)
+
+const testExport = `{"db": [{
+	"meta": {"exported_on": 1588000000000, "version": "3.15.1"},
+	"data": {
+		"posts": [{
+			"id": "1",
+			"feature_image": "__GHOST_URL__/content/images/2020/05/feature.png",
+			"html": "<img src=\"/content/images/2020/05/inline.jpg\"><img src=\"https://elsewhere.com/content/images/x.png\">"
+		}],
+		"users": [{"id": "2", "profile_image": "%s/content/images/2020/05/gone.jpg"}]
+	}
+}]}`
+
+// fakeGhost serves just enough of the Admin API and content images to take
+// and restore backups. Redirects are served as JSON, as by Ghost 3, unless
+// redirectsYAML is set.
+func fakeGhost(t *testing.T, redirectsYAML string, opts ...ghost.AdminClientOption) (*ghost.AdminClient, map[string]string, func()) {
+	uploads := make(map[string]string)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc(ghost.BaseAdminPath+"db", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprintf(w, testExport, server.URL)
+			return
+		}
+		uploads["db"] = formFile(t, r, "importfile")
+		fmt.Fprint(w, `{"problems": []}`)
+	})
+	mux.HandleFunc(ghost.BaseAdminPath+"redirects/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `[{"from": "/a", "to": "/b"}]`)
+			return
+		}
+		uploads["redirects"] = formFile(t, r, "redirects")
+	})
+	if redirectsYAML != "" {
+		mux.HandleFunc(ghost.BaseAdminPath+"redirects/download/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, redirectsYAML)
+		})
+		mux.HandleFunc(ghost.BaseAdminPath+"redirects/upload/", func(w http.ResponseWriter, r *http.Request) {
+			uploads["redirects"] = formFile(t, r, "redirects")
+		})
+	}
+	mux.HandleFunc(ghost.BaseAdminPath+"settings/routes/yaml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, "routes:\n")
+			return
+		}
+		uploads["routes"] = formFile(t, r, "routes")
+	})
+	mux.HandleFunc(ghost.BaseAdminPath+"themes/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"themes": [{"name": "casper", "active": false}, {"name": "edge", "active": true}]}`)
+	})
+	mux.HandleFunc(ghost.BaseAdminPath+"themes/edge/download", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "zip")
+	})
+	mux.HandleFunc("/content/images/2020/05/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/content/images/2020/05/gone.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, r.URL.Path)
+	})
+
+	client, err := ghost.NewAdminClient(server.URL, &http.Client{}, opts...)
+	require.NoError(t, err)
+	return client, uploads, server.Close
+}
+
+func formFile(t *testing.T, r *http.Request, field string) string {
+	file, _, err := r.FormFile(field)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(file)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// readArchive returns the contents of the entries of the named archive.
+func readArchive(t *testing.T, storage Storage, name string) map[string]string {
+	rc, err := storage.Get(name)
+	require.NoError(t, err)
+	defer rc.Close()
+	gz, err := gzip.NewReader(rc)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	contents := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		b, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		contents[hdr.Name] = string(b)
+	}
+	return contents
+}
+
+func TestBackupRestore(t *testing.T) {
+	client, uploads, teardown := fakeGhost(t, "")
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage := &LocalStorage{Dir: dir}
+
+	manifest, err := Backup(context.Background(), client, "blog", storage, nil)
+	require.NoError(t, err)
+	require.Equal(t, "3.15.1", manifest.Version)
+	require.Equal(t, "edge", manifest.Theme)
+	require.Equal(t, 2, manifest.Images)
+	require.Len(t, manifest.MissingImages, 1)
+
+	archives, err := Archives(storage, "blog")
+	require.NoError(t, err)
+	require.Len(t, archives, 1)
+	require.Equal(t, ArchiveName("blog", manifest.CreatedAt), archives[0].Name)
+
+	contents := readArchive(t, storage, archives[0].Name)
+	require.Contains(t, contents, ManifestEntry)
+	require.Contains(t, contents, DatabaseEntry)
+	require.Contains(t, contents, RedirectsEntry)
+	require.Equal(t, "routes:\n", contents[RoutesEntry])
+	require.Equal(t, "zip", contents[ThemeDir+"edge.zip"])
+	require.Equal(t, "/content/images/2020/05/feature.png", contents[ImagesDir+"2020/05/feature.png"])
+	require.Contains(t, contents, ImagesDir+"2020/05/inline.jpg")
+	require.Len(t, contents, 7)
+
+	problems, err := Restore(context.Background(), client, storage, archives[0].Name, nil)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+	require.Equal(t, contents[DatabaseEntry], uploads["db"])
+	require.JSONEq(t, `[{"from": "/a", "to": "/b"}]`, uploads["redirects"])
+	require.Equal(t, "routes:\n", uploads["routes"])
+}
+
+func TestBackupRestore_redirectsYAML(t *testing.T) {
+	// the redirect would fail linting, but is restored all the same.
+	const redirects = "302:\n  ^/a: /a/\n"
+	client, uploads, teardown := fakeGhost(t, redirects, ghost.WithRedirectLint(nil))
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage := &LocalStorage{Dir: dir}
+
+	_, err = Backup(context.Background(), client, "blog", storage, &Options{SkipTheme: true, SkipImages: true})
+	require.NoError(t, err)
+	names, err := storage.List()
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+	contents := readArchive(t, storage, names[0])
+	require.Equal(t, redirects, contents[RedirectsYAMLEntry])
+	require.NotContains(t, contents, RedirectsEntry)
+
+	_, err = Restore(context.Background(), client, storage, names[0], nil)
+	require.NoError(t, err)
+	require.Equal(t, redirects, uploads["redirects"])
+}
+
+func TestBackup_canceled(t *testing.T) {
+	client, _, teardown := fakeGhost(t, "")
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage := &LocalStorage{Dir: dir}
+
+	// the database export, like every other request, stops with the backup.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Backup(ctx, client, "blog", storage, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.Canceled.Error())
+	names, err := storage.List()
+	require.NoError(t, err)
+	require.Empty(t, names)
+}
+
+func TestRestore_canceled(t *testing.T) {
+	client, uploads, teardown := fakeGhost(t, "")
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage := &LocalStorage{Dir: dir}
+	manifest, err := Backup(context.Background(), client, "blog", storage, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Restore(ctx, client, storage, ArchiveName("blog", manifest.CreatedAt), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.Canceled.Error())
+	require.Empty(t, uploads)
+}
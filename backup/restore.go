@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// RestoreOptions configure what a restore brings back.
+type RestoreOptions struct {
+	// Theme uploads and activates the archived theme.
+	Theme bool
+}
+
+// Restore restores the database, redirects and routes, plus optionally the
+// theme, from the named archive. The database is imported on top of any
+// existing content, so restoring into an empty site is recommended. Content
+// images cannot be uploaded to their original paths through the Admin API,
+// so they are left in the archive for restoring onto the content directory
+// by hand. Redirects are uploaded as archived, without linting them, so that
+// the backup of a site can always be restored. Returns the problems reported
+// by the database import, if any. Cancelling ctx stops the restore, including
+// any upload in progress.
+func Restore(ctx context.Context, client *ghost.AdminClient, storage Storage, name string, opts *RestoreOptions) ([]*ghost.DatabaseImportProblem, error) {
+	if opts == nil {
+		opts = &RestoreOptions{}
+	}
+	client = client.WithContext(ctx)
+
+	rc, err := storage.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var problems []*ghost.DatabaseImportProblem
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return problems, err
+		}
+
+		switch {
+		case hdr.Name == DatabaseEntry:
+			problems, _, err = client.Database.ImportFrom(tr)
+		case hdr.Name == RedirectsEntry:
+			_, err = client.Redirects.UploadRaw(tr, ghost.RedirectsJSON)
+		case hdr.Name == RedirectsYAMLEntry:
+			_, err = client.Redirects.UploadRaw(tr, ghost.RedirectsYAML)
+		case hdr.Name == RoutesEntry:
+			_, err = client.Routes.UploadFile(tr)
+		case opts.Theme && strings.HasPrefix(hdr.Name, ThemeDir):
+			var theme *ghost.Theme
+			theme, _, err = client.Themes.Upload(path.Base(hdr.Name), tr)
+			if err == nil && theme.Name != nil {
+				_, _, err = client.Themes.Activate(*theme.Name)
+			}
+		}
+		if err != nil {
+			return problems, fmt.Errorf("failed to restore %s: %v", hdr.Name, err)
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,144 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	archiveExt = ".tar.gz"
+	// archiveTimeLayout parses archive times, with or without the
+	// milliseconds of archiveNameLayout, which older names lack.
+	archiveTimeLayout = "20060102T150405Z"
+	archiveNameLayout = "20060102T150405.000Z"
+)
+
+// Archive identifies a single stored backup of a site.
+type Archive struct {
+	Name string
+	Site string
+	Time time.Time
+}
+
+// ArchiveName returns the storage name of the backup of site taken at t, to
+// the millisecond.
+func ArchiveName(site string, t time.Time) string {
+	return fmt.Sprintf("%s-%s%s", site, t.UTC().Format(archiveNameLayout), archiveExt)
+}
+
+// ParseArchiveName parses a name produced by ArchiveName.
+func ParseArchiveName(name string) (*Archive, error) {
+	base := strings.TrimSuffix(name, archiveExt)
+	i := strings.LastIndex(base, "-")
+	if base == name || i <= 0 {
+		return nil, fmt.Errorf("%q is not a backup archive name", name)
+	}
+
+	t, err := time.Parse(archiveTimeLayout, base[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a backup archive name: %v", name, err)
+	}
+	return &Archive{Name: name, Site: base[:i], Time: t}, nil
+}
+
+// Retention decides which archives of a site are kept. An archive is kept if
+// it is one of the KeepLast most recent archives, or the most recent archive
+// of one of the KeepDaily most recent days, KeepWeekly most recent ISO weeks
+// or KeepMonthly most recent months which have archives. A zero Retention
+// keeps everything.
+type Retention struct {
+	KeepLast    int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+}
+
+func (r Retention) keepsAll() bool {
+	return r.KeepLast == 0 && r.KeepDaily == 0 && r.KeepWeekly == 0 && r.KeepMonthly == 0
+}
+
+// Expired returns the archives which the policy does not keep, most recent
+// first. Archives are expected to be of a single site.
+func (r Retention) Expired(archives []*Archive) []*Archive {
+	if r.keepsAll() {
+		return nil
+	}
+
+	sorted := make([]*Archive, len(archives))
+	copy(sorted, archives)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+
+	buckets := []struct {
+		keep   int
+		period func(t time.Time) string
+		seen   map[string]bool
+	}{
+		{r.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }, map[string]bool{}},
+		{r.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}, map[string]bool{}},
+		{r.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }, map[string]bool{}},
+	}
+
+	var expired []*Archive
+	for i, archive := range sorted {
+		keep := i < r.KeepLast
+		for b := range buckets {
+			bucket := &buckets[b]
+			period := bucket.period(archive.Time.UTC())
+			if bucket.seen[period] || len(bucket.seen) >= bucket.keep {
+				continue
+			}
+			bucket.seen[period] = true
+			keep = true
+		}
+		if !keep {
+			expired = append(expired, archive)
+		}
+	}
+	return expired
+}
+
+// Archives returns the archives of site held in storage, most recent first.
+// Names which are not backup archives are ignored.
+func Archives(storage Storage, site string) ([]*Archive, error) {
+	names, err := storage.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []*Archive
+	for _, name := range names {
+		archive, err := ParseArchiveName(name)
+		if err != nil || archive.Site != site {
+			continue
+		}
+		archives = append(archives, archive)
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Time.After(archives[j].Time)
+	})
+	return archives, nil
+}
+
+// Prune deletes the archives of site which retention does not keep, returning
+// the deleted archives.
+func Prune(storage Storage, site string, retention Retention) ([]*Archive, error) {
+	archives, err := Archives(storage, site)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := retention.Expired(archives)
+	for i, archive := range expired {
+		if err := storage.Delete(archive.Name); err != nil {
+			return expired[:i], err
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,61 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchiveName(t *testing.T) {
+	at := time.Date(2020, 5, 1, 3, 4, 5, 6e6, time.UTC)
+	name := ArchiveName("my-blog", at)
+	require.Equal(t, "my-blog-20200501T030405.006Z.tar.gz", name)
+
+	archive, err := ParseArchiveName(name)
+	require.NoError(t, err)
+	require.Equal(t, "my-blog", archive.Site)
+	require.True(t, at.Equal(archive.Time))
+
+	// names without milliseconds are still recognized.
+	archive, err = ParseArchiveName("my-blog-20200501T030405Z.tar.gz")
+	require.NoError(t, err)
+	require.True(t, at.Truncate(time.Second).Equal(archive.Time))
+
+	_, err = ParseArchiveName("notes.txt")
+	require.Error(t, err)
+}
+
+func TestRetention_Expired(t *testing.T) {
+	// one archive every 12 hours for 60 days, most recent on 2020-03-01.
+	end := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	var archives []*Archive
+	for i := 0; i < 120; i++ {
+		at := end.Add(-time.Duration(i) * 12 * time.Hour)
+		archives = append(archives, &Archive{Name: ArchiveName("a", at), Site: "a", Time: at})
+	}
+
+	expired := Retention{KeepLast: 3, KeepDaily: 3, KeepWeekly: 2, KeepMonthly: 3}.Expired(archives)
+	kept := make(map[string]bool)
+	for _, archive := range archives {
+		kept[archive.Name] = true
+	}
+	for _, archive := range expired {
+		delete(kept, archive.Name)
+	}
+
+	want := []time.Time{
+		end,                      // last, daily 03-01, weekly 2020-W09, monthly 03
+		end.Add(-12 * time.Hour), // last
+		end.Add(-24 * time.Hour), // last, daily 02-29, monthly 02
+		end.Add(-48 * time.Hour), // daily 02-28
+		time.Date(2020, 2, 23, 12, 0, 0, 0, time.UTC), // weekly 2020-W08
+		time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC), // monthly 01
+	}
+	require.Len(t, kept, len(want))
+	for _, at := range want {
+		require.True(t, kept[ArchiveName("a", at)], "expected %v to be kept", at)
+	}
+
+	require.Nil(t, Retention{}.Expired(archives))
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// A Runner periodically backs up every site of a Registry, pruning old
+// archives according to its Retention after each backup.
+type Runner struct {
+	Registry  *ghost.Registry
+	Storage   Storage
+	Retention Retention
+	Options   *Options
+
+	// Interval is the time between runs.
+	Interval time.Duration
+	// Parallelism bounds how many sites are backed up at once.
+	Parallelism int
+	// ErrorLog receives the errors of failed runs. Defaults to the standard
+	// logger.
+	ErrorLog *log.Logger
+}
+
+// RunOnce backs up and prunes every site. Failures are collected into a
+// ghost.SiteErrors.
+func (r *Runner) RunOnce(ctx context.Context) error {
+	return r.Registry.ForEach(ctx, r.Parallelism, func(ctx context.Context, site string, client *ghost.AdminClient) error {
+		if _, err := Backup(ctx, client, site, r.Storage, r.Options); err != nil {
+			return err
+		}
+		_, err := Prune(r.Storage, site, r.Retention)
+		return err
+	})
+}
+
+// Run calls RunOnce immediately and then every Interval until ctx is done,
+// logging the errors of failed runs. It returns the context's error.
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.logf("backup run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) logf(format string, args ...interface{}) {
+	if r.ErrorLog != nil {
+		r.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Storage is where backup archives are kept. Names are flat, without any
+// directory components.
+type Storage interface {
+	// Put stores the contents of r under name, replacing any existing archive.
+	Put(name string, r io.Reader) error
+	// Get opens the named archive for reading.
+	Get(name string) (io.ReadCloser, error)
+	// List returns the names of all stored archives.
+	List() ([]string, error)
+	// Delete removes the named archive.
+	Delete(name string) error
+}
+
+// LocalStorage keeps archives as files in a local directory.
+type LocalStorage struct {
+	Dir string
+}
+
+// Put writes the archive to a temporary file first, renaming it into place
+// once complete, so a failed backup never leaves a partial archive behind.
+func (s *LocalStorage) Put(name string, r io.Reader) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.Dir, "."+name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.Dir, name))
+}
+
+// Get opens the named archive.
+func (s *LocalStorage) Get(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.Dir, name))
+}
+
+// List returns the archives in the directory, sorted by name. Temporary
+// files of backups in progress are skipped.
+func (s *LocalStorage) List() ([]string, error) {
+	infos, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, info := range infos {
+		if info.IsDir() || info.Name()[0] == '.' {
+			continue
+		}
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes the named archive.
+func (s *LocalStorage) Delete(name string) error {
+	return os.Remove(filepath.Join(s.Dir, name))
+}
@@ -0,0 +1,105 @@
+// Command ghost-backup backs up and restores the Ghost sites of a registry
+// config file, keeping archives in a local directory.
+//
+// Usage:
+//
+//	ghost-backup -config sites.json -dir /var/backups/ghost [flags] run|once
+//	ghost-backup -config sites.json -dir /var/backups/ghost list <site>
+//	ghost-backup -config sites.json -dir /var/backups/ghost restore <site> <archive>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/backup"
+)
+
+func main() {
+	var (
+		config      = flag.String("config", "sites.json", "registry config file of the sites")
+		dir         = flag.String("dir", "backups", "directory archives are kept in")
+		interval    = flag.Duration("interval", 24*time.Hour, "time between scheduled runs")
+		parallelism = flag.Int("parallelism", 4, "number of sites backed up at once")
+		keepLast    = flag.Int("keep-last", 0, "number of most recent archives to keep")
+		keepDaily   = flag.Int("keep-daily", 7, "number of daily archives to keep")
+		keepWeekly  = flag.Int("keep-weekly", 4, "number of weekly archives to keep")
+		keepMonthly = flag.Int("keep-monthly", 12, "number of monthly archives to keep")
+		skipTheme   = flag.Bool("skip-theme", false, "leave the active theme out of archives")
+		skipImages  = flag.Bool("skip-images", false, "leave content images out of archives")
+		theme       = flag.Bool("restore-theme", false, "upload and activate the archived theme on restore")
+	)
+	flag.Parse()
+
+	registry, err := ghost.LoadRegistry(*config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	runner := &backup.Runner{
+		Registry: registry,
+		Storage:  &backup.LocalStorage{Dir: *dir},
+		Retention: backup.Retention{
+			KeepLast:    *keepLast,
+			KeepDaily:   *keepDaily,
+			KeepWeekly:  *keepWeekly,
+			KeepMonthly: *keepMonthly,
+		},
+		Options: &backup.Options{
+			SkipTheme:  *skipTheme,
+			SkipImages: *skipImages,
+		},
+		Interval:    *interval,
+		Parallelism: *parallelism,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	switch flag.Arg(0) {
+	case "run":
+		runner.Run(ctx)
+	case "once":
+		if err := runner.RunOnce(ctx); err != nil {
+			log.Fatal(err)
+		}
+	case "list":
+		archives, err := backup.Archives(runner.Storage, flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, archive := range archives {
+			fmt.Println(archive.Name)
+		}
+	case "restore":
+		if flag.NArg() != 3 {
+			log.Fatal("usage: ghost-backup restore <site> <archive>")
+		}
+		client, err := registry.Client(flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		problems, err := backup.Restore(ctx, client, runner.Storage, flag.Arg(2), &backup.RestoreOptions{Theme: *theme})
+		for _, problem := range problems {
+			fmt.Printf("%s: %s\n", problem.Message, problem.Context)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
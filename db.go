@@ -1,8 +1,13 @@
 package ghost
 
 import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 )
 
@@ -17,8 +22,8 @@ type DatabaseMeta struct {
 
 // Database is the representation of the database, with meta info.
 type Database struct {
-	Meta *DatabaseMeta          `json:"meta"`
-	Data map[string]interface{} `json:"data"`
+	Meta *DatabaseMeta `json:"meta"`
+	Data *DatabaseData `json:"data"`
 }
 
 // DatabaseImportProblem represents any issues or strageness encountered during import.
@@ -27,6 +32,13 @@ type DatabaseImportProblem struct {
 	Help    string                 `json:"help"`
 	Context string                 `json:"context"`
 	Err     map[string]interface{} `json:"err"`
+
+	// Table, RowID, RowSlug and Kind are parsed from the above. RowSlug
+	// holds the key of settings.
+	Table   string      `json:"-"`
+	RowID   string      `json:"-"`
+	RowSlug string      `json:"-"`
+	Kind    ProblemKind `json:"-"`
 }
 
 type databaseImportWrapper struct {
@@ -39,26 +51,26 @@ type databaseWrapper struct {
 }
 
 // Export the database.
-func (s *AdminDatabaseService) Export() (*Database, error) {
+func (s *AdminDatabaseService) Export() (*Database, *Response, error) {
 	req, err := s.client.NewRequest("GET", "db", nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	dbWrapper := new(databaseWrapper)
-	_, err = s.client.Do(req, dbWrapper)
+	resp, err := s.client.Do(req, dbWrapper)
 	if err != nil {
-		return nil, err
+		return nil, newResponse(resp), err
 	}
 
 	if len(dbWrapper.DB) != 1 {
-		return nil, fmt.Errorf("received unexpected response format")
+		return nil, newResponse(resp), fmt.Errorf("received unexpected response format")
 	}
-	return dbWrapper.DB[0], nil
+	return dbWrapper.DB[0], newResponse(resp), nil
 }
 
 // Import the database. Returns the list of problems (warnings), if any.
-func (s *AdminDatabaseService) Import(db *Database) ([]*DatabaseImportProblem, error) {
+func (s *AdminDatabaseService) Import(db *Database) ([]*DatabaseImportProblem, *Response, error) {
 	dbPartWriter := func(mpw *multipart.Writer) error {
 		part, err := createFormFile(mpw, "importfile", "ghost.json", "application/json")
 		if err != nil {
@@ -71,14 +83,118 @@ func (s *AdminDatabaseService) Import(db *Database) ([]*DatabaseImportProblem, e
 
 	req, err := s.client.NewUploadRequest("db", dbPartWriter, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	wrapper := new(databaseImportWrapper)
+	resp, err := s.client.Do(req, wrapper)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+
+	return wrapper.Problems, newResponse(resp), nil
+}
+
+// ExportOptions configure how ExportTo writes the export.
+type ExportOptions struct {
+	// Gzip compresses the export as it is written.
+	Gzip bool
+}
+
+// ExportSummary describes the export written by ExportTo.
+type ExportSummary struct {
+	// Size is the number of bytes written, after any compression.
+	Size int64
+	// SHA256 is the hex encoded checksum of the bytes written.
+	SHA256 string
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// ExportTo streams the raw database export to w, without decoding it into
+// memory. The export keeps the wrapping Ghost responds with, which Import and
+// ImportFrom both accept.
+func (s *AdminDatabaseService) ExportTo(w io.Writer, opts *ExportOptions) (*ExportSummary, *Response, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	req, err := s.client.NewRequest("GET", "db", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(w, hash)}
+	var dst io.Writer = counter
+	var gz *gzip.Writer
+	if opts.Gzip {
+		gz = gzip.NewWriter(counter)
+		dst = gz
+	}
+
+	resp, err := s.client.Do(req, dst)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, newResponse(resp), err
+		}
+	}
+
+	summary := &ExportSummary{
+		Size:   counter.n,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}
+	return summary, newResponse(resp), nil
+}
+
+// ImportFrom imports a raw database export read from r, as written by
+// ExportTo, streaming it to Ghost rather than buffering it in memory. Gzip
+// compressed exports are detected and decompressed. Returns the list of
+// problems (warnings), if any.
+func (s *AdminDatabaseService) ImportFrom(r io.Reader) ([]*DatabaseImportProblem, *Response, error) {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	dbPartWriter := func(mpw *multipart.Writer) error {
+		part, err := createFormFile(mpw, "importfile", "ghost.json", "application/json")
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, src)
+		return err
+	}
+
+	req, err := s.client.NewStreamingUploadRequest("db", dbPartWriter, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	wrapper := new(databaseImportWrapper)
-	_, err = s.client.Do(req, wrapper)
+	resp, err := s.client.Do(req, wrapper)
 	if err != nil {
-		return nil, err
+		return nil, newResponse(resp), err
 	}
 
-	return wrapper.Problems, nil
+	return wrapper.Problems, newResponse(resp), nil
 }
@@ -0,0 +1,124 @@
+package ghost
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testExport = `{
+	"meta": {"exported_on": 1588000000000, "version": "3.15.1"},
+	"data": {
+		"posts": [{
+			"id": "5ddc9141c35e7700383b2937",
+			"title": "Welcome",
+			"slug": "welcome",
+			"html": "<p>Hi & welcome</p>",
+			"featured": 0,
+			"custom_excerpt": null,
+			"newsletter_id": "abc"
+		}],
+		"tags": [{"id": "t1", "name": "News", "slug": "news", "accent_color": "#fff"}],
+		"posts_tags": [{"id": "pt1", "post_id": "5ddc9141c35e7700383b2937", "tag_id": "t1", "sort_order": 0}],
+		"members": [],
+		"api_keys": [{"id": "k1", "secret": "s"}]
+	}
+}`
+
+func TestDatabase_roundTrip(t *testing.T) {
+	db := new(Database)
+	require.NoError(t, json.Unmarshal([]byte(testExport), db))
+
+	post := db.Data.Posts[0]
+	require.Equal(t, "Welcome", *post.Title)
+	require.False(t, post.Featured.Value)
+	require.Nil(t, post.CustomExcerpt)
+	require.Equal(t, json.RawMessage(`"abc"`), post.Extra["newsletter_id"])
+	require.Equal(t, json.RawMessage(`null`), post.Extra["custom_excerpt"])
+	require.Equal(t, 0, *db.Data.PostsTags[0].SortOrder)
+	require.Contains(t, db.Data.Extra, "api_keys")
+
+	b, err := json.Marshal(db)
+	require.NoError(t, err)
+
+	var want, got interface{}
+	require.NoError(t, json.Unmarshal([]byte(testExport), &want))
+	require.NoError(t, json.Unmarshal(b, &got))
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("round trip produced %s, want %s", b, testExport)
+	}
+}
+
+func TestDatabase_typedFieldsWin(t *testing.T) {
+	row := new(PostRow)
+	require.NoError(t, json.Unmarshal([]byte(`{"id": "1", "title": null}`), row))
+	row.Title = String("Set")
+
+	b, err := json.Marshal(row)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": "1", "title": "Set"}`, string(b))
+}
+
+func TestDatabaseService_Export(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"db", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"db": [%s]}`, testExport)
+	})
+
+	db, _, err := client.Database.Export()
+	require.NoError(t, err)
+	require.Equal(t, "3.15.1", db.Meta.Version)
+	require.Len(t, db.Data.Posts, 1)
+	require.Len(t, db.Data.Tags, 1)
+}
+
+func TestDatabaseService_ExportTo_ImportFrom(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	body := fmt.Sprintf(`{"db": [%s]}`, testExport)
+	mux.HandleFunc(BaseAdminPath+"db", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, body)
+			return
+		}
+
+		testMethod(t, r, "POST")
+		file, _, err := r.FormFile("importfile")
+		require.NoError(t, err)
+		uploaded, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		require.Equal(t, body, string(uploaded))
+		fmt.Fprint(w, `{"problems": [{"message": "Duplicate entry found. Import skipped.", "context": "Tag: news"}]}`)
+	})
+
+	buf := &bytes.Buffer{}
+	summary, _, err := client.Database.ExportTo(buf, &ExportOptions{Gzip: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), summary.Size)
+	sum := sha256.Sum256(buf.Bytes())
+	require.Equal(t, hex.EncodeToString(sum[:]), summary.SHA256)
+
+	gz, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	exported, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, body, string(exported))
+
+	problems, _, err := client.Database.ImportFrom(buf)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	require.Equal(t, "Tag: news", problems[0].Context)
+}
@@ -0,0 +1,472 @@
+package ghost
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Columns holds the columns of a row, or tables of DatabaseData, which have
+// no typed field, keyed by name. Typed columns that were null or empty in the
+// source are kept here too, so that exports round-trip losslessly, even those
+// from newer Ghost versions.
+type Columns map[string]json.RawMessage
+
+// Flag is a boolean column. Depending on the database backing Ghost, booleans
+// are exported either as true/false or as 1/0; the form read is preserved when
+// writing the Flag back out.
+type Flag struct {
+	Value   bool
+	numeric bool
+}
+
+// NewFlag returns a pointer to the Flag with value b.
+func NewFlag(b bool) *Flag {
+	return &Flag{Value: b}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f Flag) MarshalJSON() ([]byte, error) {
+	switch {
+	case f.numeric && f.Value:
+		return []byte("1"), nil
+	case f.numeric:
+		return []byte("0"), nil
+	case f.Value:
+		return []byte("true"), nil
+	default:
+		return []byte("false"), nil
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*f = Flag{Value: b}
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*f = Flag{Value: n != 0, numeric: true}
+	return nil
+}
+
+// DatabaseData holds the tables of a Ghost export. Tables without a typed
+// field are kept in Extra.
+type DatabaseData struct {
+	Posts        []*PostRow       `json:"posts,omitempty"`
+	PostsMeta    []*PostMetaRow   `json:"posts_meta,omitempty"`
+	Tags         []*TagRow        `json:"tags,omitempty"`
+	PostsTags    []*PostTagRow    `json:"posts_tags,omitempty"`
+	PostsAuthors []*PostAuthorRow `json:"posts_authors,omitempty"`
+	Users        []*UserRow       `json:"users,omitempty"`
+	Roles        []*RoleRow       `json:"roles,omitempty"`
+	RolesUsers   []*RoleUserRow   `json:"roles_users,omitempty"`
+	Settings     []*SettingRow    `json:"settings,omitempty"`
+	Members      []*MemberRow     `json:"members,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// PostRow is a row of the posts table, holding both posts and pages.
+type PostRow struct {
+	ID                *string `json:"id,omitempty"`
+	UUID              *string `json:"uuid,omitempty"`
+	Title             *string `json:"title,omitempty"`
+	Slug              *string `json:"slug,omitempty"`
+	Mobiledoc         *string `json:"mobiledoc,omitempty"`
+	Lexical           *string `json:"lexical,omitempty"`
+	HTML              *string `json:"html,omitempty"`
+	CommentID         *string `json:"comment_id,omitempty"`
+	Plaintext         *string `json:"plaintext,omitempty"`
+	FeatureImage      *string `json:"feature_image,omitempty"`
+	Featured          *Flag   `json:"featured,omitempty"`
+	Type              *string `json:"type,omitempty"`
+	Status            *string `json:"status,omitempty"`
+	Locale            *string `json:"locale,omitempty"`
+	Visibility        *string `json:"visibility,omitempty"`
+	AuthorID          *string `json:"author_id,omitempty"`
+	CreatedAt         *string `json:"created_at,omitempty"`
+	CreatedBy         *string `json:"created_by,omitempty"`
+	UpdatedAt         *string `json:"updated_at,omitempty"`
+	UpdatedBy         *string `json:"updated_by,omitempty"`
+	PublishedAt       *string `json:"published_at,omitempty"`
+	PublishedBy       *string `json:"published_by,omitempty"`
+	CustomExcerpt     *string `json:"custom_excerpt,omitempty"`
+	CodeinjectionHead *string `json:"codeinjection_head,omitempty"`
+	CodeinjectionFoot *string `json:"codeinjection_foot,omitempty"`
+	CustomTemplate    *string `json:"custom_template,omitempty"`
+	CanonicalURL      *string `json:"canonical_url,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// PostMetaRow is a row of the posts_meta table, holding the social and seo
+// metadata of a post.
+type PostMetaRow struct {
+	ID                 *string `json:"id,omitempty"`
+	PostID             *string `json:"post_id,omitempty"`
+	OgImage            *string `json:"og_image,omitempty"`
+	OgTitle            *string `json:"og_title,omitempty"`
+	OgDescription      *string `json:"og_description,omitempty"`
+	TwitterImage       *string `json:"twitter_image,omitempty"`
+	TwitterTitle       *string `json:"twitter_title,omitempty"`
+	TwitterDescription *string `json:"twitter_description,omitempty"`
+	MetaTitle          *string `json:"meta_title,omitempty"`
+	MetaDescription    *string `json:"meta_description,omitempty"`
+	EmailSubject       *string `json:"email_subject,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// TagRow is a row of the tags table.
+type TagRow struct {
+	ID              *string `json:"id,omitempty"`
+	Name            *string `json:"name,omitempty"`
+	Slug            *string `json:"slug,omitempty"`
+	Description     *string `json:"description,omitempty"`
+	FeatureImage    *string `json:"feature_image,omitempty"`
+	ParentID        *string `json:"parent_id,omitempty"`
+	Visibility      *string `json:"visibility,omitempty"`
+	MetaTitle       *string `json:"meta_title,omitempty"`
+	MetaDescription *string `json:"meta_description,omitempty"`
+	CreatedAt       *string `json:"created_at,omitempty"`
+	CreatedBy       *string `json:"created_by,omitempty"`
+	UpdatedAt       *string `json:"updated_at,omitempty"`
+	UpdatedBy       *string `json:"updated_by,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// PostTagRow is a row of the posts_tags join table.
+type PostTagRow struct {
+	ID        *string `json:"id,omitempty"`
+	PostID    *string `json:"post_id,omitempty"`
+	TagID     *string `json:"tag_id,omitempty"`
+	SortOrder *int    `json:"sort_order,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// PostAuthorRow is a row of the posts_authors join table.
+type PostAuthorRow struct {
+	ID        *string `json:"id,omitempty"`
+	PostID    *string `json:"post_id,omitempty"`
+	AuthorID  *string `json:"author_id,omitempty"`
+	SortOrder *int    `json:"sort_order,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// UserRow is a row of the users table, i.e. staff users.
+type UserRow struct {
+	ID              *string `json:"id,omitempty"`
+	Name            *string `json:"name,omitempty"`
+	Slug            *string `json:"slug,omitempty"`
+	Password        *string `json:"password,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	ProfileImage    *string `json:"profile_image,omitempty"`
+	CoverImage      *string `json:"cover_image,omitempty"`
+	Bio             *string `json:"bio,omitempty"`
+	Website         *string `json:"website,omitempty"`
+	Location        *string `json:"location,omitempty"`
+	Facebook        *string `json:"facebook,omitempty"`
+	Twitter         *string `json:"twitter,omitempty"`
+	Accessibility   *string `json:"accessibility,omitempty"`
+	Status          *string `json:"status,omitempty"`
+	Locale          *string `json:"locale,omitempty"`
+	Visibility      *string `json:"visibility,omitempty"`
+	MetaTitle       *string `json:"meta_title,omitempty"`
+	MetaDescription *string `json:"meta_description,omitempty"`
+	Tour            *string `json:"tour,omitempty"`
+	LastSeen        *string `json:"last_seen,omitempty"`
+	CreatedAt       *string `json:"created_at,omitempty"`
+	CreatedBy       *string `json:"created_by,omitempty"`
+	UpdatedAt       *string `json:"updated_at,omitempty"`
+	UpdatedBy       *string `json:"updated_by,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// RoleRow is a row of the roles table.
+type RoleRow struct {
+	ID          *string `json:"id,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	CreatedAt   *string `json:"created_at,omitempty"`
+	CreatedBy   *string `json:"created_by,omitempty"`
+	UpdatedAt   *string `json:"updated_at,omitempty"`
+	UpdatedBy   *string `json:"updated_by,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// RoleUserRow is a row of the roles_users join table.
+type RoleUserRow struct {
+	ID     *string `json:"id,omitempty"`
+	RoleID *string `json:"role_id,omitempty"`
+	UserID *string `json:"user_id,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// SettingRow is a row of the settings table.
+type SettingRow struct {
+	ID        *string `json:"id,omitempty"`
+	Group     *string `json:"group,omitempty"`
+	Key       *string `json:"key,omitempty"`
+	Value     *string `json:"value,omitempty"`
+	Type      *string `json:"type,omitempty"`
+	Flags     *string `json:"flags,omitempty"`
+	CreatedAt *string `json:"created_at,omitempty"`
+	CreatedBy *string `json:"created_by,omitempty"`
+	UpdatedAt *string `json:"updated_at,omitempty"`
+	UpdatedBy *string `json:"updated_by,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// MemberRow is a row of the members table.
+type MemberRow struct {
+	ID          *string `json:"id,omitempty"`
+	UUID        *string `json:"uuid,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	Note        *string `json:"note,omitempty"`
+	Geolocation *string `json:"geolocation,omitempty"`
+	Subscribed  *Flag   `json:"subscribed,omitempty"`
+	CreatedAt   *string `json:"created_at,omitempty"`
+	CreatedBy   *string `json:"created_by,omitempty"`
+	UpdatedAt   *string `json:"updated_at,omitempty"`
+	UpdatedBy   *string `json:"updated_by,omitempty"`
+
+	Extra Columns `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d DatabaseData) MarshalJSON() ([]byte, error) {
+	type plain DatabaseData
+	return marshalColumns(plain(d), d.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *DatabaseData) UnmarshalJSON(data []byte) error {
+	type plain DatabaseData
+	return unmarshalColumns(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r PostRow) MarshalJSON() ([]byte, error) {
+	type plain PostRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *PostRow) UnmarshalJSON(data []byte) error {
+	type plain PostRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r PostMetaRow) MarshalJSON() ([]byte, error) {
+	type plain PostMetaRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *PostMetaRow) UnmarshalJSON(data []byte) error {
+	type plain PostMetaRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r TagRow) MarshalJSON() ([]byte, error) {
+	type plain TagRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *TagRow) UnmarshalJSON(data []byte) error {
+	type plain TagRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r PostTagRow) MarshalJSON() ([]byte, error) {
+	type plain PostTagRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *PostTagRow) UnmarshalJSON(data []byte) error {
+	type plain PostTagRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r PostAuthorRow) MarshalJSON() ([]byte, error) {
+	type plain PostAuthorRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *PostAuthorRow) UnmarshalJSON(data []byte) error {
+	type plain PostAuthorRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r UserRow) MarshalJSON() ([]byte, error) {
+	type plain UserRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *UserRow) UnmarshalJSON(data []byte) error {
+	type plain UserRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r RoleRow) MarshalJSON() ([]byte, error) {
+	type plain RoleRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *RoleRow) UnmarshalJSON(data []byte) error {
+	type plain RoleRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r RoleUserRow) MarshalJSON() ([]byte, error) {
+	type plain RoleUserRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *RoleUserRow) UnmarshalJSON(data []byte) error {
+	type plain RoleUserRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r SettingRow) MarshalJSON() ([]byte, error) {
+	type plain SettingRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *SettingRow) UnmarshalJSON(data []byte) error {
+	type plain SettingRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r MemberRow) MarshalJSON() ([]byte, error) {
+	type plain MemberRow
+	return marshalColumns(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *MemberRow) UnmarshalJSON(data []byte) error {
+	type plain MemberRow
+	return unmarshalColumns(data, (*plain)(r), &r.Extra)
+}
+
+// columnFields caches, per struct type, the index of each typed field keyed by
+// its json name.
+var columnFields sync.Map
+
+func typedColumns(t reflect.Type) map[string]int {
+	if fields, ok := columnFields.Load(t); ok {
+		return fields.(map[string]int)
+	}
+
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	columnFields.Store(t, fields)
+	return fields
+}
+
+func encodeJSON(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// marshalColumns encodes the typed fields of v, which must be a struct with
+// omitempty fields, merged with the columns of extra. Typed fields that are
+// set take precedence over extra columns of the same name.
+func marshalColumns(v interface{}, extra Columns) ([]byte, error) {
+	typed, err := encodeJSON(v)
+	if err != nil || len(extra) == 0 {
+		return typed, err
+	}
+
+	merged := make(map[string]json.RawMessage, len(extra))
+	for name, value := range extra {
+		merged[name] = value
+	}
+	if err := json.Unmarshal(typed, &merged); err != nil {
+		return nil, err
+	}
+	return encodeJSON(merged)
+}
+
+// unmarshalColumns decodes data into the typed fields of v, a pointer to a
+// struct, collecting into extra the unknown columns as well as those which
+// the typed fields would omit when encoded, such as nulls and empty tables.
+func unmarshalColumns(data []byte, v interface{}, extra *Columns) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	fields := typedColumns(rv.Type())
+	*extra = nil
+	for name, value := range raw {
+		if i, ok := fields[name]; ok && !isOmitted(rv.Field(i)) {
+			continue
+		}
+		if *extra == nil {
+			*extra = make(Columns)
+		}
+		(*extra)[name] = value
+	}
+	return nil
+}
+
+// isOmitted reports whether the omitempty field v would be left out when
+// encoded.
+func isOmitted(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+// Package diff compares two Ghost database exports table by table, reporting
+// the rows added, removed and modified between them.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// FieldChange is the change of a single column of a row. Old or New is null
+// when the column was absent on that side.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// RowChange is a row that was modified, keyed by its id.
+type RowChange struct {
+	ID      string         `json:"id"`
+	Label   string         `json:"label,omitempty"`
+	Changes []*FieldChange `json:"changes"`
+}
+
+// Row identifies a row that was added or removed.
+type Row struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+// TableDiff holds the differences within a single table.
+type TableDiff struct {
+	Table    string       `json:"table"`
+	Added    []*Row       `json:"added,omitempty"`
+	Removed  []*Row       `json:"removed,omitempty"`
+	Modified []*RowChange `json:"modified,omitempty"`
+}
+
+// Report holds the differences between two exports, for the tables which
+// differ, ordered by table name.
+type Report struct {
+	Tables []*TableDiff `json:"tables"`
+}
+
+// Empty reports whether the exports were identical.
+func (r *Report) Empty() bool {
+	return len(r.Tables) == 0
+}
+
+// labelColumns are the columns used, in order of preference, to give a row a
+// human readable label alongside its id.
+var labelColumns = []string{"slug", "key", "email", "name", "title"}
+
+type row map[string]json.RawMessage
+
+// Compare returns the differences from oldDB to newDB. Rows are matched by
+// their id column; rows without an id are matched by their entire contents,
+// so are only ever reported as added or removed. Columns that are null and
+// columns that are absent are considered equal.
+func Compare(oldDB, newDB *ghost.Database) (*Report, error) {
+	oldTables, err := tables(oldDB)
+	if err != nil {
+		return nil, err
+	}
+	newTables, err := tables(newDB)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for name := range oldTables {
+		names[name] = true
+	}
+	for name := range newTables {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	report := &Report{Tables: []*TableDiff{}}
+	for _, name := range sorted {
+		td, err := compareTable(name, oldTables[name], newTables[name])
+		if err != nil {
+			return nil, err
+		}
+		if len(td.Added) > 0 || len(td.Removed) > 0 || len(td.Modified) > 0 {
+			report.Tables = append(report.Tables, td)
+		}
+	}
+	return report, nil
+}
+
+// tables decodes the data of db into generic rows per table, which covers the
+// typed tables and those kept in Extra alike.
+func tables(db *ghost.Database) (map[string][]row, error) {
+	if db == nil || db.Data == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(db.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string][]row, len(raw))
+	for name, value := range raw {
+		var rows []row
+		if err := json.Unmarshal(value, &rows); err != nil {
+			// not a table of rows; compare it as a single row.
+			rows = []row{{"value": value}}
+		}
+		tables[name] = rows
+	}
+	return tables, nil
+}
+
+func compareTable(name string, oldRows, newRows []row) (*TableDiff, error) {
+	td := &TableDiff{Table: name}
+
+	oldByKey, oldKeys, err := index(oldRows)
+	if err != nil {
+		return nil, err
+	}
+	newByKey, newKeys, err := index(newRows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range oldKeys {
+		o := oldByKey[key]
+		n, ok := newByKey[key]
+		if !ok {
+			td.Removed = append(td.Removed, &Row{ID: id(o), Label: label(o)})
+			continue
+		}
+
+		changes, err := compareRows(o, n)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			td.Modified = append(td.Modified, &RowChange{ID: id(n), Label: label(n), Changes: changes})
+		}
+	}
+	for _, key := range newKeys {
+		if _, ok := oldByKey[key]; !ok {
+			n := newByKey[key]
+			td.Added = append(td.Added, &Row{ID: id(n), Label: label(n)})
+		}
+	}
+	return td, nil
+}
+
+// index keys rows by id, or canonical contents for rows without one,
+// returning the keys in their original order.
+func index(rows []row) (map[string]row, []string, error) {
+	byKey := make(map[string]row, len(rows))
+	keys := make([]string, 0, len(rows))
+	for _, r := range rows {
+		key := "id:" + id(r)
+		if id(r) == "" {
+			c, err := canonical(json.RawMessage(mustMarshal(r)))
+			if err != nil {
+				return nil, nil, err
+			}
+			key = "row:" + c
+		}
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = r
+	}
+	return byKey, keys, nil
+}
+
+func compareRows(o, n row) ([]*FieldChange, error) {
+	fields := make(map[string]bool)
+	for f := range o {
+		fields[f] = true
+	}
+	for f := range n {
+		fields[f] = true
+	}
+	sorted := make([]string, 0, len(fields))
+	for f := range fields {
+		sorted = append(sorted, f)
+	}
+	sort.Strings(sorted)
+
+	var changes []*FieldChange
+	for _, f := range sorted {
+		ov, err := canonical(o[f])
+		if err != nil {
+			return nil, err
+		}
+		nv, err := canonical(n[f])
+		if err != nil {
+			return nil, err
+		}
+		if ov != nv {
+			changes = append(changes, &FieldChange{Field: f, Old: json.RawMessage(ov), New: json.RawMessage(nv)})
+		}
+	}
+	return changes, nil
+}
+
+// canonical re-encodes value with sorted keys and no insignificant
+// whitespace, treating an absent value as null.
+func canonical(value json.RawMessage) (string, error) {
+	if len(value) == 0 {
+		return "null", nil
+	}
+
+	// numbers are kept as written, as float64 would round large ids and
+	// counts.
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+func mustMarshal(r row) []byte {
+	b, _ := json.Marshal(r)
+	return b
+}
+
+func stringColumn(r row, column string) string {
+	var s string
+	if value, ok := r[column]; ok {
+		json.Unmarshal(value, &s)
+	}
+	return s
+}
+
+func id(r row) string {
+	return stringColumn(r, "id")
+}
+
+func label(r row) string {
+	for _, column := range labelColumns {
+		if s := stringColumn(r, column); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// WriteText writes a human readable rendering of the report to w.
+func (r *Report) WriteText(w io.Writer) error {
+	if r.Empty() {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, td := range r.Tables {
+		fmt.Fprintf(buf, "%s: %d added, %d removed, %d modified\n",
+			td.Table, len(td.Added), len(td.Removed), len(td.Modified))
+		for _, row := range td.Added {
+			fmt.Fprintf(buf, "  + %s\n", describe(row.ID, row.Label))
+		}
+		for _, row := range td.Removed {
+			fmt.Fprintf(buf, "  - %s\n", describe(row.ID, row.Label))
+		}
+		for _, row := range td.Modified {
+			fmt.Fprintf(buf, "  ~ %s\n", describe(row.ID, row.Label))
+			for _, change := range row.Changes {
+				fmt.Fprintf(buf, "      %s: %s -> %s\n", change.Field, truncate(change.Old), truncate(change.New))
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r *Report) String() string {
+	buf := &bytes.Buffer{}
+	r.WriteText(buf)
+	return buf.String()
+}
+
+func describe(id, label string) string {
+	if id == "" {
+		return label
+	}
+	if label == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", id, label)
+}
+
+// maxValueLen bounds the length of values in the text rendering, as columns
+// such as html and mobiledoc are long.
+const maxValueLen = 80
+
+func truncate(value json.RawMessage) string {
+	s := string(value)
+	if len(s) <= maxValueLen {
+		return s
+	}
+	// cut at the start of a character, so as not to split it.
+	n := maxValueLen - 3
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
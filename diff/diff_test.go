@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, s string) *ghost.Database {
+	db := new(ghost.Database)
+	require.NoError(t, json.Unmarshal([]byte(s), db))
+	return db
+}
+
+func TestCompare(t *testing.T) {
+	old := parse(t, `{"meta": {"version": "3.15.1"}, "data": {
+		"posts": [
+			{"id": "1", "slug": "kept", "title": "Old title", "custom_excerpt": null},
+			{"id": "2", "slug": "gone", "title": "Gone"}
+		],
+		"tags": [{"id": "t1", "slug": "news"}],
+		"labels": [{"name": "vip"}]
+	}}`)
+	current := parse(t, `{"meta": {"version": "3.15.1"}, "data": {
+		"posts": [
+			{"id": "1", "slug": "kept", "title": "New title"},
+			{"id": "3", "slug": "fresh", "title": "Fresh"}
+		],
+		"tags": [{"id": "t1", "slug": "news"}],
+		"labels": [{"name": "vip"}, {"name": "free"}]
+	}}`)
+
+	report, err := Compare(old, current)
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 2)
+
+	labels := report.Tables[0]
+	require.Equal(t, "labels", labels.Table)
+	require.Equal(t, []*Row{{Label: "free"}}, labels.Added)
+
+	posts := report.Tables[1]
+	require.Equal(t, []*Row{{ID: "3", Label: "fresh"}}, posts.Added)
+	require.Equal(t, []*Row{{ID: "2", Label: "gone"}}, posts.Removed)
+	require.Equal(t, []*RowChange{{
+		ID:    "1",
+		Label: "kept",
+		Changes: []*FieldChange{
+			{Field: "title", Old: json.RawMessage(`"Old title"`), New: json.RawMessage(`"New title"`)},
+		},
+	}}, posts.Modified)
+
+	require.Equal(t, `labels: 1 added, 0 removed, 0 modified
+  + free
+posts: 1 added, 1 removed, 1 modified
+  + 3 (fresh)
+  - 2 (gone)
+  ~ 1 (kept)
+      title: "Old title" -> "New title"
+`, report.String())
+
+	b, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `{"field":"title","old":"Old title","new":"New title"}`)
+}
+
+func TestCompare_identical(t *testing.T) {
+	db := parse(t, `{"data": {"posts": [{"id": "1"}]}}`)
+	report, err := Compare(db, db)
+	require.NoError(t, err)
+	require.True(t, report.Empty())
+	require.Equal(t, "no differences\n", report.String())
+}
+
+func TestCompare_precision(t *testing.T) {
+	old := parse(t, `{"data": {"posts": [{"id": "1", "slug": "é", "views": 9007199254740992}]}}`)
+	current := parse(t, `{"data": {"posts": [{"id": "1", "slug": "é", "views": 9007199254740993}]}}`)
+
+	// the counts differ beyond the precision of float64.
+	report, err := Compare(old, current)
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 1)
+	require.Equal(t, []*FieldChange{
+		{Field: "views", Old: json.RawMessage(`9007199254740992`), New: json.RawMessage(`9007199254740993`)},
+	}, report.Tables[0].Modified[0].Changes)
+
+	// long values are cut between characters.
+	value := json.RawMessage(`"` + strings.Repeat("é", 50) + `"`)
+	s := truncate(value)
+	require.True(t, utf8.ValidString(s))
+	require.True(t, len(s) <= maxValueLen)
+	require.True(t, strings.HasSuffix(s, "é..."))
+}
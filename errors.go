@@ -0,0 +1,50 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// APIError is a single error as reported by the Ghost API.
+type APIError struct {
+	ID       string `json:"id"`
+	Message  string `json:"message"`
+	Context  string `json:"context"`
+	Help     string `json:"help"`
+	Type     string `json:"type"`
+	Code     string `json:"code"`
+	Property string `json:"property"`
+}
+
+// ErrorResponse is returned by AdminClient.Do when the API responds with a
+// non-2xx status. Errors holds whatever errors Ghost reported in the body.
+type ErrorResponse struct {
+	Response *http.Response
+	Errors   []*APIError `json:"errors"`
+}
+
+func (r *ErrorResponse) Error() string {
+	msg := fmt.Sprintf("received %v status from API", r.Response.StatusCode)
+	if len(r.Errors) > 0 && r.Errors[0].Message != "" {
+		msg += ": " + r.Errors[0].Message
+	}
+	return msg
+}
+
+// checkResponse returns an *ErrorResponse if resp has a non-2xx status.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	errorResponse := &ErrorResponse{Response: resp}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err == nil && len(data) > 0 {
+		// the body is not guaranteed to be json, e.g. from a proxy in front
+		// of Ghost, so failing to decode it is not an error in itself.
+		json.Unmarshal(data, errorResponse)
+	}
+	return errorResponse
+}
@@ -25,7 +25,7 @@ func ExampleNewAdminClient() {
 	client.Posts.List(nil)
 }
 
-func ExampleNewAdminClient_Session() {
+func ExampleNewAdminClient_session() {
 	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	if err != nil {
 		log.Fatal(err)
@@ -37,7 +37,7 @@ func ExampleNewAdminClient_Session() {
 		log.Fatal(err)
 	}
 
-	err = client.Session.Create("username", "password")
+	_, err = client.Session.Create("username", "password")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,197 @@
+package ghost
+
+import "time"
+
+// exportFilterPageSize is the number of posts fetched per List call by
+// ExportFilter.
+const exportFilterPageSize = 100
+
+// exportTimeFormat is the form of timestamps in Ghost exports.
+const exportTimeFormat = "2006-01-02T15:04:05.000Z"
+
+// ExportFilter builds an export holding just the posts matching filter, in
+// Ghost's NQL filter syntax such as "tag:news" or "authors:jane", along with
+// their tags, authors, author roles and the join rows between them. Unlike
+// Export it is built from List calls, so it holds no settings, members or
+// other tables, but Import accepts it on any site of the same or a newer
+// major version. Author passwords are not exported, so imported authors need
+// to reset theirs.
+func (s *AdminDatabaseService) ExportFilter(filter string) (*Database, *Response, error) {
+	version, resp, err := s.siteVersion()
+	if err != nil {
+		return nil, resp, err
+	}
+
+	b := &exportBuilder{
+		data:  &DatabaseData{},
+		tags:  make(map[string]bool),
+		users: make(map[string]bool),
+		roles: make(map[string]bool),
+	}
+	params := &ListParams{Filter: filter, Limit: exportFilterPageSize, Page: 1}
+	for {
+		posts, r, err := (*AdminPostsService)(s).List(params)
+		resp = r
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, post := range posts.Posts {
+			b.addPost(post)
+		}
+
+		if posts.Meta == nil || posts.Meta.Pagination == nil || posts.Meta.Pagination.Next == nil {
+			break
+		}
+		params.Page = *posts.Meta.Pagination.Next
+	}
+
+	db := &Database{
+		Meta: &DatabaseMeta{Version: version, ExportedOn: time.Now().UnixNano() / int64(time.Millisecond)},
+		Data: b.data,
+	}
+	return db, resp, nil
+}
+
+// exportBuilder accumulates the rows of posts fetched through the API,
+// adding each tag, user and role once.
+type exportBuilder struct {
+	data  *DatabaseData
+	tags  map[string]bool
+	users map[string]bool
+	roles map[string]bool
+}
+
+func exportTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	return String(t.UTC().Format(exportTimeFormat))
+}
+
+func (b *exportBuilder) addPost(post *Post) {
+	row := &PostRow{
+		ID:                post.ID,
+		UUID:              post.UUID,
+		Title:             post.Title,
+		Slug:              post.Slug,
+		Mobiledoc:         post.Mobiledoc,
+		Lexical:           post.Lexical,
+		HTML:              post.HTML,
+		CommentID:         post.CommentID,
+		FeatureImage:      post.FeatureImage,
+		Type:              String("post"),
+		Status:            post.Status,
+		Visibility:        post.Visibility,
+		CreatedAt:         exportTime(post.CreatedAt),
+		UpdatedAt:         exportTime(post.UpdatedAt),
+		PublishedAt:       exportTime(post.PublishedAt),
+		CustomExcerpt:     post.CustomExcerpt,
+		CodeinjectionHead: post.CodeinjectionHead,
+		CodeinjectionFoot: post.CodeinjectionFoot,
+		CustomTemplate:    post.CustomTemplate,
+		CanonicalURL:      post.CanonicalURL,
+	}
+	if post.Featured != nil {
+		row.Featured = NewFlag(*post.Featured)
+	}
+	if post.PrimaryAuthor != nil {
+		row.AuthorID = post.PrimaryAuthor.ID
+	}
+	b.data.Posts = append(b.data.Posts, row)
+
+	meta := &PostMetaRow{
+		OgImage:            post.OgImage,
+		OgTitle:            post.OgTitle,
+		OgDescription:      post.OgDescription,
+		TwitterImage:       post.TwitterImage,
+		TwitterTitle:       post.TwitterTitle,
+		TwitterDescription: post.TwitterDescription,
+		MetaTitle:          post.MetaTitle,
+		MetaDescription:    post.MetaDescription,
+	}
+	if anySet(meta.OgImage, meta.OgTitle, meta.OgDescription, meta.TwitterImage,
+		meta.TwitterTitle, meta.TwitterDescription, meta.MetaTitle, meta.MetaDescription) {
+		meta.PostID = post.ID
+		b.data.PostsMeta = append(b.data.PostsMeta, meta)
+	}
+
+	for i, tag := range post.Tags {
+		b.addTag(tag)
+		b.data.PostsTags = append(b.data.PostsTags, &PostTagRow{PostID: post.ID, TagID: tag.ID, SortOrder: Int(i)})
+	}
+	for i, author := range post.Authors {
+		b.addUser(author)
+		b.data.PostsAuthors = append(b.data.PostsAuthors, &PostAuthorRow{PostID: post.ID, AuthorID: author.ID, SortOrder: Int(i)})
+	}
+}
+
+func anySet(values ...*string) bool {
+	for _, v := range values {
+		if v != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *exportBuilder) addTag(tag *Tag) {
+	if b.tags[deref(tag.ID)] {
+		return
+	}
+	b.tags[deref(tag.ID)] = true
+
+	b.data.Tags = append(b.data.Tags, &TagRow{
+		ID:              tag.ID,
+		Name:            tag.Name,
+		Slug:            tag.Slug,
+		Description:     tag.Description,
+		FeatureImage:    tag.FeatureImage,
+		Visibility:      tag.Visibility,
+		MetaTitle:       tag.MetaTitle,
+		MetaDescription: tag.MetaDescription,
+		CreatedAt:       exportTime(tag.CreatedAt),
+		UpdatedAt:       exportTime(tag.UpdatedAt),
+	})
+}
+
+func (b *exportBuilder) addUser(author *Author) {
+	if b.users[deref(author.ID)] {
+		return
+	}
+	b.users[deref(author.ID)] = true
+
+	b.data.Users = append(b.data.Users, &UserRow{
+		ID:              author.ID,
+		Name:            author.Name,
+		Slug:            author.Slug,
+		Email:           author.Email,
+		ProfileImage:    author.ProfileImage,
+		CoverImage:      author.CoverImage,
+		Bio:             author.Bio,
+		Website:         author.Website,
+		Location:        author.Location,
+		Facebook:        author.Facebook,
+		Twitter:         author.Twitter,
+		Accessibility:   author.Accessibility,
+		Status:          author.Status,
+		MetaTitle:       author.MetaTitle,
+		MetaDescription: author.MetaDescription,
+		LastSeen:        exportTime(author.LastSeen),
+		CreatedAt:       exportTime(author.CreatedAt),
+		UpdatedAt:       exportTime(author.UpdatedAt),
+	})
+
+	for _, role := range author.Roles {
+		if !b.roles[deref(role.ID)] {
+			b.roles[deref(role.ID)] = true
+			b.data.Roles = append(b.data.Roles, &RoleRow{
+				ID:          role.ID,
+				Name:        role.Name,
+				Description: role.Description,
+				CreatedAt:   exportTime(role.CreatedAt),
+				UpdatedAt:   exportTime(role.UpdatedAt),
+			})
+		}
+		b.data.RolesUsers = append(b.data.RolesUsers, &RoleUserRow{RoleID: role.ID, UserID: author.ID})
+	}
+}
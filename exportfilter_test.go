@@ -0,0 +1,60 @@
+package ghost
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseService_ExportFilter(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"site/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"site": {"title": "Blog", "version": "3.15"}}`)
+	})
+	mux.HandleFunc(BaseAdminPath+"posts", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		require.Equal(t, "tag:news", r.URL.Query().Get("filter"))
+
+		author := `{"id": "u1", "slug": "jane", "roles": [{"id": "r1", "name": "Author"}]}`
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprintf(w, `{"posts": [{
+				"id": "p1", "slug": "one", "featured": true, "meta_title": "One",
+				"created_at": "2020-05-01T12:00:00.000+02:00",
+				"tags": [{"id": "t1", "slug": "news"}, {"id": "t2", "slug": "world"}],
+				"authors": [%s], "primary_author": %s
+			}], "meta": {"pagination": {"page": 1, "pages": 2, "next": 2}}}`, author, author)
+		case "2":
+			fmt.Fprintf(w, `{"posts": [{
+				"id": "p2", "slug": "two",
+				"tags": [{"id": "t1", "slug": "news"}],
+				"authors": [%s]
+			}], "meta": {"pagination": {"page": 2, "pages": 2, "next": null}}}`, author)
+		}
+	})
+
+	db, _, err := client.Database.ExportFilter("tag:news")
+	require.NoError(t, err)
+	require.Equal(t, "3.15", db.Meta.Version)
+	require.Empty(t, db.Validate("3.15"))
+
+	data := db.Data
+	require.Len(t, data.Posts, 2)
+	require.Equal(t, "2020-05-01T10:00:00.000Z", *data.Posts[0].CreatedAt)
+	require.Equal(t, "u1", *data.Posts[0].AuthorID)
+	require.True(t, data.Posts[0].Featured.Value)
+	require.Len(t, data.PostsMeta, 1)
+	require.Equal(t, "p1", *data.PostsMeta[0].PostID)
+
+	require.Len(t, data.Tags, 2)
+	require.Len(t, data.PostsTags, 3)
+	require.Equal(t, 1, *data.PostsTags[1].SortOrder)
+	require.Len(t, data.Users, 1)
+	require.Len(t, data.PostsAuthors, 2)
+	require.Len(t, data.Roles, 1)
+	require.Len(t, data.RolesUsers, 1)
+}
@@ -28,8 +28,11 @@ func (m Meta) String() string {
 }
 
 // QueryParams are query params that can be used for get and list requests.
+// Include is a comma separated list of relations, such as "tags,authors", and
+// Formats of content formats, such as "html,mobiledoc".
 type QueryParams struct {
-	// TODO
+	Include string `url:"include,omitempty"`
+	Formats string `url:"formats,omitempty"`
 }
 
 // ListParams are params that can be used for list requests.
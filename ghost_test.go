@@ -2,6 +2,7 @@ package ghost
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -53,3 +54,14 @@ func testFormValues(t *testing.T, r *http.Request, values map[string]string) {
 		t.Errorf("Request parameters: %v, want %v", got, want)
 	}
 }
+
+func testBody(t *testing.T, r *http.Request, want string) {
+	t.Helper()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Errorf("Error reading request body: %v", err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("request Body is %s, want %s", got, want)
+	}
+}
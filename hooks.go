@@ -0,0 +1,102 @@
+package ghost
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestHook is called with every request just before it is sent.
+type RequestHook func(req *http.Request)
+
+// ResponseHook is called once every request completes, successfully or not.
+// resp is nil if no response was received.
+type ResponseHook func(req *http.Request, resp *http.Response, duration time.Duration, err error)
+
+// Logger is the structured logger used by AdminClient. Its method set is a
+// subset of that of *slog.Logger, which may be used directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithRequestHook registers hook to run before every request.
+func WithRequestHook(hook RequestHook) AdminClientOption {
+	return func(c *AdminClient) {
+		c.requestHooks = append(c.requestHooks, hook)
+	}
+}
+
+// WithResponseHook registers hook to run after every request.
+func WithResponseHook(hook ResponseHook) AdminClientOption {
+	return func(c *AdminClient) {
+		c.responseHooks = append(c.responseHooks, hook)
+	}
+}
+
+// WithLogger logs every request made through the client. The method, path,
+// status and duration are logged at info level, or error level for failures
+// along with any Ghost error type and code. Request and response headers are
+// logged at debug level with credentials redacted.
+func WithLogger(logger Logger) AdminClientOption {
+	return func(c *AdminClient) {
+		c.requestHooks = append(c.requestHooks, func(req *http.Request) {
+			logger.Debug("ghost request",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"headers", RedactHeaders(req.Header))
+		})
+		c.responseHooks = append(c.responseHooks, func(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+			args := []interface{}{
+				"method", req.Method,
+				"path", req.URL.Path,
+				"duration", duration,
+			}
+			if resp != nil {
+				args = append(args, "status", resp.StatusCode)
+				logger.Debug("ghost response headers", "path", req.URL.Path, "headers", RedactHeaders(resp.Header))
+			}
+			if err == nil {
+				logger.Info("ghost request completed", args...)
+				return
+			}
+
+			if errResp, ok := err.(*ErrorResponse); ok && len(errResp.Errors) > 0 {
+				args = append(args, "ghost_error_type", errResp.Errors[0].Type)
+				if errResp.Errors[0].Code != "" {
+					args = append(args, "ghost_error_code", errResp.Errors[0].Code)
+				}
+			}
+			args = append(args, "error", err)
+			logger.Error("ghost request failed", args...)
+		})
+	}
+}
+
+// redactedHeaders are the headers carrying credentials, either the admin
+// token or the session cookie.
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// RedactHeaders returns a copy of h with the values of credential bearing
+// headers replaced, making it safe to log.
+func RedactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := redacted[name]; ok {
+			redacted[name] = []string{"[REDACTED]"}
+		}
+	}
+	return redacted
+}
+
+func (c *AdminClient) beforeRequest(req *http.Request) {
+	for _, hook := range c.requestHooks {
+		hook(req)
+	}
+}
+
+func (c *AdminClient) afterResponse(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+	for _, hook := range c.responseHooks {
+		hook(req, resp, duration, err)
+	}
+}
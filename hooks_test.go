@@ -0,0 +1,98 @@
+package ghost
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type logEntry struct {
+	level string
+	msg   string
+	args  map[string]interface{}
+}
+
+type testLogger struct {
+	entries []*logEntry
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	e := &logEntry{level: level, msg: msg, args: make(map[string]interface{})}
+	for i := 0; i+1 < len(args); i += 2 {
+		e.args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, e)
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("debug", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("info", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("error", msg, args) }
+
+func TestAdminClient_hooks(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"posts/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{ "posts": [{"id": "1"}] }`)
+	})
+
+	var before, after int
+	WithRequestHook(func(req *http.Request) {
+		before++
+	})(client)
+	WithResponseHook(func(req *http.Request, resp *http.Response, d time.Duration, err error) {
+		after++
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})(client)
+
+	_, _, err := client.Posts.Get("1")
+	require.NoError(t, err)
+	require.Equal(t, 1, before)
+	require.Equal(t, 1, after)
+}
+
+func TestAdminClient_logger(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"posts/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": [{"message": "Post not found.", "type": "NotFoundError", "code": "POST_NOT_FOUND", "context": null}]}`)
+	})
+
+	logger := &testLogger{}
+	WithLogger(logger)(client)
+
+	_, _, err := client.Posts.Get("2")
+	require.Error(t, err)
+	require.Equal(t, "received 404 status from API: Post not found.", err.Error())
+
+	errResp, ok := err.(*ErrorResponse)
+	require.True(t, ok)
+	require.Equal(t, "NotFoundError", errResp.Errors[0].Type)
+
+	last := logger.entries[len(logger.entries)-1]
+	require.Equal(t, "error", last.level)
+	require.Equal(t, "GET", last.args["method"])
+	require.Equal(t, BaseAdminPath+"posts/2", last.args["path"])
+	require.Equal(t, 404, last.args["status"])
+	require.Equal(t, "NotFoundError", last.args["ghost_error_type"])
+	require.Equal(t, "POST_NOT_FOUND", last.args["ghost_error_code"])
+}
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Ghost abc")
+	h.Set("Cookie", "ghost-admin-api-session=abc")
+	h.Set("User-Agent", "go-ghost")
+
+	redacted := RedactHeaders(h)
+	require.Equal(t, "[REDACTED]", redacted.Get("Authorization"))
+	require.Equal(t, "[REDACTED]", redacted.Get("Cookie"))
+	require.Equal(t, "go-ghost", redacted.Get("User-Agent"))
+	require.Equal(t, "Ghost abc", h.Get("Authorization"))
+}
@@ -0,0 +1,75 @@
+// Package render holds the HTML helpers shared by the mobiledoc and lexical
+// renderers, whose output must match.
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EscapeText and EscapeAttr escape as the DOM serializer of Ghost's renderer.
+var (
+	EscapeText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+	EscapeAttr = strings.NewReplacer("&", "&amp;", `"`, "&quot;").Replace
+)
+
+// SanitizeHref neutralizes javascript: links as Ghost's renderers do.
+func SanitizeHref(href string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "javascript:") {
+		return "unsafe:" + href
+	}
+	return href
+}
+
+// HeadingIDs gives headings ids as Ghost does, unique within a document:
+// repeated ids get a -1, -2 and so on suffix.
+type HeadingIDs map[string]int
+
+// ID returns the id of a heading with text.
+func (ids HeadingIDs) ID(text string) string {
+	id := HeadingID(text)
+	if n, ok := ids[id]; ok {
+		ids[id] = n + 1
+		return id + "-" + strconv.Itoa(n+1)
+	}
+	ids[id] = 0
+	return id
+}
+
+// HeadingID slugifies heading text as Ghost 3 does for heading ids: lower
+// case, without punctuation, with dashes for spaces and URI encoded.
+func HeadingID(text string) string {
+	const removed = "[]!\"#$%&'()*+,./:;<=>?@\\^_{|}~`"
+	var sb strings.Builder
+	space := false
+	for _, c := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case strings.ContainsRune(removed, c):
+		case unicode.IsSpace(c):
+			space = true
+		default:
+			if space && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			space = false
+			sb.WriteRune(c)
+		}
+	}
+	return encodeURIComponent(strings.Trim(sb.String(), "-"))
+}
+
+// encodeURIComponent escapes s as the JavaScript function of the same name.
+func encodeURIComponent(s string) string {
+	const unreserved = "-_.!~*'()"
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || strings.IndexByte(unreserved, b) >= 0 {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,20 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadingIDs(t *testing.T) {
+	ids := make(HeadingIDs)
+	require.Equal(t, "hello-world", ids.ID(" Hello,  World! "))
+	require.Equal(t, "hello-world-1", ids.ID("hello world"))
+	require.Equal(t, "hello-world-2", ids.ID("Hello world?"))
+	require.Equal(t, "caf%C3%A9", ids.ID("Café"))
+}
+
+func TestSanitizeHref(t *testing.T) {
+	require.Equal(t, "unsafe: JavaScript:alert(1)", SanitizeHref(" JavaScript:alert(1)"))
+	require.Equal(t, "/about/", SanitizeHref("/about/"))
+}
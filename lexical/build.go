@@ -0,0 +1,142 @@
+package lexical
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+)
+
+// node returns a node of version 1 with fields given as keys and values.
+func node(typ string, children []*Node, keysAndValues ...interface{}) *Node {
+	n := &Node{Type: typ, Version: 1, Children: children}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		n.Set(keysAndValues[i].(string), keysAndValues[i+1])
+	}
+	return n
+}
+
+// element returns an element node as the Ghost editor creates them.
+func element(typ string, children []*Node, keysAndValues ...interface{}) *Node {
+	if children == nil {
+		children = []*Node{}
+	}
+	keysAndValues = append(keysAndValues, "direction", "ltr", "format", "", "indent", 0)
+	return node(typ, children, keysAndValues...)
+}
+
+// NewDocument returns a document of the top-level nodes.
+func NewDocument(nodes ...*Node) *Document {
+	return &Document{Root: element("root", nodes)}
+}
+
+// Paragraph returns a paragraph.
+func Paragraph(children ...*Node) *Node {
+	return element("paragraph", children)
+}
+
+// Heading returns a heading of level 1 to 6, failing for other levels.
+func Heading(level int, children ...*Node) (*Node, error) {
+	if level < 1 || level > 6 {
+		return nil, fmt.Errorf("lexical: invalid heading level %d", level)
+	}
+	return element("heading", children, "tag", fmt.Sprintf("h%d", level)), nil
+}
+
+// Quote returns a block quote.
+func Quote(children ...*Node) *Node {
+	return element("quote", children)
+}
+
+// List returns a bulleted list, or a numbered one if ordered, with an item
+// per list of nodes.
+func List(ordered bool, items ...[]*Node) *Node {
+	listType, tag := "bullet", "ul"
+	if ordered {
+		listType, tag = "number", "ol"
+	}
+	children := make([]*Node, len(items))
+	for i, item := range items {
+		children[i] = element("listitem", item, "value", i+1)
+	}
+	return element("list", children, "listType", listType, "start", 1, "tag", tag)
+}
+
+// Text returns a text node in format, e.g. FormatBold|FormatItalic.
+func Text(text string, format Format) *Node {
+	return node("text", nil, "detail", 0, "format", int(format), "mode", "normal", "style", "", "text", text)
+}
+
+// Link returns a link to url.
+func Link(url string, children ...*Node) *Node {
+	return element("link", children, "rel", nil, "target", nil, "title", nil, "url", url)
+}
+
+// LineBreak returns a line break within a block.
+func LineBreak() *Node {
+	return node("linebreak", nil)
+}
+
+// Card returns the card of payload p, one of the card types of package
+// mobiledoc.
+func Card(p mobiledoc.CardPayload) (*Node, error) {
+	card, err := mobiledoc.NewCard(p)
+	if err != nil {
+		return nil, err
+	}
+	return cardNode(card.Name, card.Payload)
+}
+
+// cardNodeTypes map mobiledoc card names to lexical node types, where they
+// differ.
+var cardNodeTypes = map[string]string{
+	"card-markdown": "markdown",
+	"hr":            "horizontalrule",
+	"code":          "codeblock",
+}
+
+// cardNode returns the card node of a mobiledoc card, whose payload
+// properties become fields of the node.
+func cardNode(name string, payload json.RawMessage) (*Node, error) {
+	var fields map[string]json.RawMessage
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return nil, err
+		}
+	}
+	typ := name
+	if t, ok := cardNodeTypes[name]; ok {
+		typ = t
+	}
+	delete(fields, "cardName")
+	if typ == "embed" {
+		if t, ok := fields["type"]; ok {
+			fields["embedType"] = t
+			delete(fields, "type")
+		}
+	}
+	n := &Node{Type: typ, Version: 1}
+	if len(fields) > 0 {
+		n.Fields = fields
+	}
+	return n, nil
+}
+
+// mobiledocCard returns the mobiledoc card of a card node, for rendering.
+func mobiledocCard(n *Node) (*mobiledoc.Card, error) {
+	fields := make(map[string]json.RawMessage, len(n.Fields))
+	for key, value := range n.Fields {
+		fields[key] = value
+	}
+	if n.Type == "embed" {
+		if t, ok := fields["embedType"]; ok {
+			fields["type"] = t
+			delete(fields, "embedType")
+		}
+	}
+	payload, err := marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &mobiledoc.Card{Name: n.Type, Payload: payload}, nil
+}
@@ -0,0 +1,166 @@
+// Package lexical reads, builds and renders lexical, the JSON document format
+// of the Ghost 5 editor kept in Post.Lexical, and converts mobiledoc
+// documents of earlier Ghost versions to it.
+package lexical
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Format is the bit set of formats of a text node.
+type Format int
+
+// Text formats.
+const (
+	FormatBold Format = 1 << iota
+	FormatItalic
+	FormatStrikethrough
+	FormatUnderline
+	FormatCode
+	FormatSubscript
+	FormatSuperscript
+	FormatHighlight
+)
+
+// Document is a lexical document.
+type Document struct {
+	Root *Node `json:"root"`
+}
+
+// Node is a lexical node: an element such as "paragraph" or "list", a "text"
+// node, or a card such as "image" or "markdown". Fields holds all other
+// properties of the node, such as the text of text nodes or the payload of
+// cards, so that documents round-trip losslessly. Children is nil for nodes
+// without children.
+type Node struct {
+	Type     string
+	Version  int
+	Children []*Node
+	Fields   map[string]json.RawMessage
+}
+
+// Get decodes the field key into v, leaving v as is when there is no such
+// field.
+func (n *Node) Get(key string, v interface{}) error {
+	raw, ok := n.Fields[key]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Set sets the field key to v.
+func (n *Node) Set(key string, v interface{}) error {
+	raw, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	if n.Fields == nil {
+		n.Fields = make(map[string]json.RawMessage)
+	}
+	n.Fields[key] = raw
+	return nil
+}
+
+// Decode decodes all fields into v, such as the payload of a card into one
+// of the card types of package mobiledoc. Embed cards hold their type in the
+// field embedType.
+func (n *Node) Decode(v interface{}) error {
+	b, err := json.Marshal(n.Fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// str and number read fields, ignoring those of other types.
+func (n *Node) str(key string) string {
+	var s string
+	n.Get(key, &s)
+	return s
+}
+
+func (n *Node) number(key string) int {
+	var i int
+	n.Get(key, &i)
+	return i
+}
+
+// marshal encodes v without escaping HTML, as Ghost does.
+func marshal(v interface{}) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
+
+// MarshalJSON implements json.Marshaler. Keys are sorted, which is also the
+// order Ghost writes them in.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(n.Fields)+3)
+	for key, value := range n.Fields {
+		fields[key] = value
+	}
+	if n.Children != nil {
+		fields["children"] = n.Children
+	}
+	fields["type"] = n.Type
+	fields["version"] = n.Version
+	return marshal(fields)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Node) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	*n = Node{}
+	if err := json.Unmarshal(fields["type"], &n.Type); err != nil {
+		return fmt.Errorf("lexical: node without type: %s", b)
+	}
+	if raw, ok := fields["version"]; ok {
+		if err := json.Unmarshal(raw, &n.Version); err != nil {
+			return err
+		}
+	}
+	if raw, ok := fields["children"]; ok {
+		n.Children = []*Node{}
+		if err := json.Unmarshal(raw, &n.Children); err != nil {
+			return err
+		}
+	}
+	delete(fields, "type")
+	delete(fields, "version")
+	delete(fields, "children")
+	if len(fields) > 0 {
+		n.Fields = fields
+	}
+	return nil
+}
+
+// Parse decodes a lexical document, such as the Lexical of a post.
+func Parse(s string) (*Document, error) {
+	doc := &Document{}
+	if err := json.Unmarshal([]byte(s), doc); err != nil {
+		return nil, err
+	}
+	if doc.Root == nil || doc.Root.Type != "root" {
+		return nil, fmt.Errorf("lexical: document without root")
+	}
+	return doc, nil
+}
+
+// Marshal encodes d as Ghost does, without escaping HTML.
+func (d *Document) Marshal() (string, error) {
+	b, err := marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
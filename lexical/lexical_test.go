@@ -0,0 +1,118 @@
+package lexical
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+	"github.com/stretchr/testify/require"
+)
+
+const testLexical = `{"root":{"children":[{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"Hi & bye","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"tag":"h2","type":"heading","version":1},{"children":[{"detail":0,"format":1,"mode":"normal","style":"","text":"bold","type":"text","version":1},{"type":"linebreak","version":1},{"children":[{"detail":0,"format":3,"mode":"normal","style":"","text":"link","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"rel":null,"target":null,"title":null,"type":"link","url":"https://ghost.org","version":1}],"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1},{"alt":"","caption":"","cardWidth":"wide","src":"/a.jpg","type":"image","version":1},{"children":[{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"one","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"listitem","value":1,"version":1}],"direction":"ltr","format":"","indent":0,"listType":"number","start":3,"tag":"ol","type":"list","version":1},{"calloutEmoji":"💡","calloutText":"<p>Note</p>","backgroundColor":"blue","type":"callout","version":1},{"embedType":"video","html":"<iframe></iframe>","type":"embed","url":"https://youtu.be/x","version":1}],"direction":"ltr","format":"","indent":0,"type":"root","version":1}}`
+
+func TestParseRender(t *testing.T) {
+	doc, err := Parse(testLexical)
+	require.NoError(t, err)
+	require.Len(t, doc.Root.Children, 6)
+	require.Equal(t, "heading", doc.Root.Children[0].Type)
+
+	var image mobiledoc.ImageCard
+	require.NoError(t, doc.Root.Children[2].Decode(&image))
+	require.Equal(t, "/a.jpg", image.Src)
+
+	html, err := doc.HTML()
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		`<h2 id="hi-bye">Hi &amp; bye</h2>`,
+		`<p><strong>bold</strong><br><a href="https://ghost.org"><strong><em>link</em></strong></a></p>`,
+		`<figure class="kg-card kg-image-card kg-width-wide"><img src="/a.jpg" class="kg-image" alt=""></figure>`,
+		`<ol start="3"><li>one</li></ol>`,
+		`<div class="kg-card kg-callout-card kg-callout-card-blue"><div class="kg-callout-emoji">💡</div><div class="kg-callout-text"><p>Note</p></div></div>`,
+		`<figure class="kg-card kg-embed-card"><iframe></iframe></figure>`,
+	}, ""), html)
+
+	s, err := doc.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, testLexical, s)
+
+	_, err = Parse(`{"root":null}`)
+	require.Error(t, err)
+}
+
+func TestBuild(t *testing.T) {
+	card, err := Card(mobiledoc.HTMLCard{HTML: "<div>raw</div>"})
+	require.NoError(t, err)
+	heading, err := Heading(1, Text("Title", 0))
+	require.NoError(t, err)
+	doc := NewDocument(
+		heading,
+		Paragraph(Text("a ", FormatCode), Link("javascript:x", Text("b", 0))),
+		List(false, []*Node{Text("x", FormatItalic|FormatUnderline)}),
+		card,
+	)
+
+	r := &Renderer{Nodes: map[string]NodeRenderer{
+		"html": func(n *Node) (string, error) { return "<!-- html -->", nil },
+	}}
+	html, err := r.Render(doc)
+	require.NoError(t, err)
+	require.Equal(t, `<h1 id="title">Title</h1><p><code>a </code><a href="unsafe:javascript:x">b</a></p><ul><li><em><u>x</u></em></li></ul><!-- html -->`, html)
+
+	s, err := doc.Marshal()
+	require.NoError(t, err)
+	parsed, err := Parse(s)
+	require.NoError(t, err)
+	again, err := parsed.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, s, again)
+	require.Contains(t, s, `{"html":"<div>raw</div>","type":"html","version":1}`)
+
+	_, err = Heading(7)
+	require.Error(t, err)
+	invalid := element("heading", nil, "tag", "h7")
+	_, err = NewDocument(invalid).HTML()
+	require.Error(t, err)
+}
+
+func TestFromMobiledoc(t *testing.T) {
+	md, err := mobiledoc.NewBuilder().
+		Heading(2, mobiledoc.Text("Hello")).
+		Paragraph(
+			mobiledoc.Text("plain "),
+			&mobiledoc.Marker{Open: []*mobiledoc.Markup{mobiledoc.NewMarkup("a", "href", "/x/")}, Text: "linked "},
+			mobiledoc.Strong("and bold"),
+			&mobiledoc.Marker{Closed: 1, Text: " end"},
+			mobiledoc.SoftReturn(),
+		).
+		List(false, []*mobiledoc.Marker{mobiledoc.Em("item")}).
+		Card(mobiledoc.EmbedCard{URL: "https://youtu.be/x", HTML: "<iframe></iframe>", Type: "video"}).
+		Document()
+	require.NoError(t, err)
+
+	doc, err := FromMobiledoc(md)
+	require.NoError(t, err)
+
+	link := doc.Root.Children[1].Children[1]
+	require.Equal(t, "link", link.Type)
+	require.Len(t, link.Children, 3)
+	require.Equal(t, "/x/", link.str("url"))
+	require.Equal(t, int(FormatBold), link.Children[1].number("format"))
+	require.Equal(t, "video", doc.Root.Children[3].str("embedType"))
+
+	mdHTML, err := md.HTML()
+	require.NoError(t, err)
+	html, err := doc.HTML()
+	require.NoError(t, err)
+	require.Equal(t, mdHTML, html)
+	require.Equal(t, `<h2 id="hello">Hello</h2><p>plain <a href="/x/">linked <strong>and bold</strong> end</a><br></p><ul><li><em>item</em></li></ul><figure class="kg-card kg-embed-card"><iframe></iframe></figure>`, html)
+
+	mobiledocJSON, err := md.Marshal()
+	require.NoError(t, err)
+	post := &ghost.Post{Mobiledoc: ghost.String(mobiledocJSON)}
+	require.NoError(t, ConvertPost(post))
+	require.Nil(t, post.Mobiledoc)
+	converted, err := Parse(*post.Lexical)
+	require.NoError(t, err)
+	require.Len(t, converted.Root.Children, 4)
+}
@@ -0,0 +1,162 @@
+package lexical
+
+import (
+	"fmt"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+)
+
+// markupFormats are the text formats of mobiledoc markups.
+var markupFormats = map[string]Format{
+	"strong": FormatBold,
+	"b":      FormatBold,
+	"em":     FormatItalic,
+	"i":      FormatItalic,
+	"s":      FormatStrikethrough,
+	"u":      FormatUnderline,
+	"code":   FormatCode,
+	"sub":    FormatSubscript,
+	"sup":    FormatSuperscript,
+}
+
+// sectionNodes map the tags of mobiledoc markup sections to node types.
+var sectionNodes = map[string]string{
+	"p":          "paragraph",
+	"blockquote": "quote",
+	"aside":      "aside",
+}
+
+// FromMobiledoc converts a mobiledoc document to lexical as Ghost does when
+// migrating posts: sections become paragraphs, headings, quotes and lists,
+// markups become text formats and links, soft returns become line breaks, and
+// cards keep their payload.
+func FromMobiledoc(doc *mobiledoc.Document) (*Document, error) {
+	var nodes []*Node
+	for _, section := range doc.Sections {
+		switch s := section.(type) {
+		case *mobiledoc.MarkupSection:
+			children, err := convertMarkers(s.Markers)
+			if err != nil {
+				return nil, err
+			}
+			if typ, ok := sectionNodes[s.Tag]; ok {
+				nodes = append(nodes, element(typ, children))
+			} else if headingTags[s.Tag] {
+				nodes = append(nodes, element("heading", children, "tag", s.Tag))
+			} else {
+				return nil, fmt.Errorf("lexical: unknown mobiledoc section tag %q", s.Tag)
+			}
+		case *mobiledoc.ImageSection:
+			nodes = append(nodes, node("image", nil, "src", s.Src))
+		case *mobiledoc.ListSection:
+			items := make([][]*Node, len(s.Items))
+			for i, item := range s.Items {
+				children, err := convertMarkers(item)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = children
+			}
+			nodes = append(nodes, List(s.Tag == "ol", items...))
+		case *mobiledoc.CardSection:
+			n, err := cardNode(s.Card.Name, s.Card.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("lexical: card %q: %v", s.Card.Name, err)
+			}
+			nodes = append(nodes, n)
+		default:
+			return nil, fmt.Errorf("lexical: unknown mobiledoc section %T", section)
+		}
+	}
+	return NewDocument(nodes...), nil
+}
+
+// convertMarkers converts mobiledoc markers to text nodes, within link nodes
+// for those inside links.
+func convertMarkers(markers []*mobiledoc.Marker) ([]*Node, error) {
+	var (
+		nodes []*Node
+		open  []*mobiledoc.Markup
+		link  *mobiledoc.Markup
+		links *Node
+	)
+	for _, m := range markers {
+		open = append(open, m.Open...)
+
+		var format Format
+		var href *mobiledoc.Markup
+		for _, markup := range open {
+			if markup.Tag == "a" {
+				href = markup
+			} else {
+				format |= markupFormats[markup.Tag]
+			}
+		}
+
+		var n *Node
+		switch {
+		case m.Atom == nil:
+			n = Text(m.Text, format)
+		case m.Atom.Name == "soft-return":
+			n = LineBreak()
+		default:
+			n = Text(m.Atom.Text, format)
+		}
+
+		if href == nil {
+			link, links = nil, nil
+			nodes = append(nodes, n)
+		} else {
+			// consecutive markers within the same link share its node.
+			if href != link {
+				link, links = href, Link(markupAttribute(href, "href"))
+				for _, attribute := range []string{"rel", "target", "title"} {
+					if value := markupAttribute(href, attribute); value != "" {
+						links.Set(attribute, value)
+					}
+				}
+				nodes = append(nodes, links)
+			}
+			links.Children = append(links.Children, n)
+		}
+
+		if m.Closed > len(open) {
+			return nil, fmt.Errorf("lexical: mobiledoc marker closes %d markups of %d open", m.Closed, len(open))
+		}
+		open = open[:len(open)-m.Closed]
+	}
+	return nodes, nil
+}
+
+func markupAttribute(markup *mobiledoc.Markup, name string) string {
+	for i := 0; i+1 < len(markup.Attributes); i += 2 {
+		if markup.Attributes[i] == name {
+			return markup.Attributes[i+1]
+		}
+	}
+	return ""
+}
+
+// ConvertPost migrates post from mobiledoc to lexical, setting Lexical and
+// clearing Mobiledoc. Posts without mobiledoc are left as they are.
+func ConvertPost(post *ghost.Post) error {
+	if post.Mobiledoc == nil || *post.Mobiledoc == "" {
+		return nil
+	}
+	md, err := mobiledoc.Parse(*post.Mobiledoc)
+	if err != nil {
+		return err
+	}
+	doc, err := FromMobiledoc(md)
+	if err != nil {
+		return err
+	}
+	s, err := doc.Marshal()
+	if err != nil {
+		return err
+	}
+	post.Lexical = ghost.String(s)
+	post.Mobiledoc = nil
+	return nil
+}
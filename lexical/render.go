@@ -0,0 +1,187 @@
+package lexical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pubbit-co/go-ghost/internal/render"
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+)
+
+// NodeRenderer renders a node to HTML.
+type NodeRenderer func(n *Node) (string, error)
+
+// Renderer renders documents to HTML as Ghost does. Cards are rendered as
+// their mobiledoc counterparts, by mobiledoc.Renderer.
+type Renderer struct {
+	// Markdown renders the markdown of markdown cards to HTML; without it,
+	// markdown cards fail to render.
+	Markdown func(markdown string) (string, error)
+	// Nodes render nodes by type, in addition to or instead of the defaults.
+	Nodes map[string]NodeRenderer
+}
+
+// HTML renders d with the default renderer.
+func (d *Document) HTML() (string, error) {
+	return (&Renderer{}).Render(d)
+}
+
+// formatTags are the tags of text formats, outermost first.
+var formatTags = []struct {
+	format Format
+	tag    string
+}{
+	{FormatBold, "strong"},
+	{FormatItalic, "em"},
+	{FormatStrikethrough, "s"},
+	{FormatUnderline, "u"},
+	{FormatCode, "code"},
+	{FormatSubscript, "sub"},
+	{FormatSuperscript, "sup"},
+	{FormatHighlight, "mark"},
+}
+
+var headingTags = map[string]bool{"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true}
+
+type renderState struct {
+	*Renderer
+	ids render.HeadingIDs
+}
+
+// Render renders doc to HTML.
+func (r *Renderer) Render(doc *Document) (string, error) {
+	if doc.Root == nil {
+		return "", nil
+	}
+	state := &renderState{Renderer: r, ids: make(render.HeadingIDs)}
+	var sb strings.Builder
+	if err := state.children(&sb, doc.Root); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (r *renderState) children(sb *strings.Builder, n *Node) error {
+	for _, child := range n.Children {
+		if err := r.node(sb, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrap renders the children of n within tag with attributes as names and
+// values, those with empty values left out.
+func (r *renderState) wrap(sb *strings.Builder, n *Node, tag string, attrs ...string) error {
+	sb.WriteString("<" + tag)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] != "" {
+			fmt.Fprintf(sb, ` %s="%s"`, attrs[i], render.EscapeAttr(attrs[i+1]))
+		}
+	}
+	sb.WriteString(">")
+	if err := r.children(sb, n); err != nil {
+		return err
+	}
+	sb.WriteString("</" + tag + ">")
+	return nil
+}
+
+func (r *renderState) node(sb *strings.Builder, n *Node) error {
+	if render, ok := r.Nodes[n.Type]; ok {
+		html, err := render(n)
+		sb.WriteString(html)
+		return err
+	}
+
+	switch n.Type {
+	case "paragraph":
+		return r.wrap(sb, n, "p")
+	case "heading":
+		tag := n.str("tag")
+		if !headingTags[tag] {
+			return fmt.Errorf("lexical: invalid heading tag %q", tag)
+		}
+		// Ghost gives headings an id, unique within the document.
+		return r.wrap(sb, n, tag, "id", r.ids.ID(textContent(n)))
+	case "quote":
+		return r.wrap(sb, n, "blockquote")
+	case "aside":
+		return r.wrap(sb, n, "blockquote", "class", "kg-blockquote-alt")
+	case "list":
+		tag := "ul"
+		var start string
+		if n.str("listType") == "number" {
+			tag = "ol"
+			if s := n.number("start"); s > 1 {
+				start = strconv.Itoa(s)
+			}
+		}
+		return r.wrap(sb, n, tag, "start", start)
+	case "listitem":
+		return r.wrap(sb, n, "li")
+	case "link":
+		return r.wrap(sb, n, "a", "href", render.SanitizeHref(n.str("url")), "rel", n.str("rel"), "target", n.str("target"), "title", n.str("title"))
+	case "text", "extended-text":
+		format := Format(n.number("format"))
+		for _, f := range formatTags {
+			if format&f.format != 0 {
+				sb.WriteString("<" + f.tag + ">")
+			}
+		}
+		sb.WriteString(render.EscapeText(n.str("text")))
+		for i := len(formatTags) - 1; i >= 0; i-- {
+			if format&formatTags[i].format != 0 {
+				sb.WriteString("</" + formatTags[i].tag + ">")
+			}
+		}
+		return nil
+	case "tab":
+		sb.WriteString("\t")
+		return nil
+	case "linebreak":
+		sb.WriteString("<br>")
+		return nil
+	case "horizontalrule":
+		sb.WriteString("<hr>")
+		return nil
+	case "codeblock":
+		var class string
+		if language := n.str("language"); language != "" {
+			class = fmt.Sprintf(` class="language-%s"`, render.EscapeAttr(language))
+		}
+		code := "<pre><code" + class + ">" + render.EscapeText(n.str("code")) + "</code></pre>"
+		if caption := n.str("caption"); caption != "" {
+			code = `<figure class="kg-card kg-code-card">` + code + "<figcaption>" + caption + "</figcaption></figure>"
+		}
+		sb.WriteString(code)
+		return nil
+	}
+
+	if n.Children != nil {
+		return fmt.Errorf("lexical: no renderer for node %q", n.Type)
+	}
+	card, err := mobiledocCard(n)
+	if err != nil {
+		return err
+	}
+	html, err := (&mobiledoc.Renderer{Markdown: r.Markdown}).RenderCard(card)
+	if err != nil {
+		return fmt.Errorf("lexical: %v", err)
+	}
+	sb.WriteString(html)
+	return nil
+}
+
+// textContent returns the plain text of n.
+func textContent(n *Node) string {
+	if n.Children == nil {
+		return n.str("text")
+	}
+	var sb strings.Builder
+	for _, child := range n.Children {
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
@@ -0,0 +1,245 @@
+// Package markdown converts Markdown files with YAML front matter into Ghost
+// posts, and such posts back into files. The body is kept as Markdown, in the
+// markdown card of a mobiledoc document, so Ghost renders it natively.
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// frontMatterDelimiter opens and closes the front matter.
+const frontMatterDelimiter = "---"
+
+// Post statuses accepted in front matter.
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusScheduled = "scheduled"
+)
+
+// ErrNoTitle is returned for files without a title in their front matter.
+var ErrNoTitle = errors.New("markdown: front matter has no title")
+
+// ErrNotMarkdown is returned by FromPost for posts whose content is not a
+// single markdown card.
+var ErrNotMarkdown = errors.New("markdown: post content is not a markdown card")
+
+// FrontMatter is the front matter of a Markdown post. Authors are given by
+// email address or slug, and tags by name. ID and UpdatedAt identify the post
+// a file was last synced with, and are set when writing posts out.
+type FrontMatter struct {
+	Title        string     `yaml:"title"`
+	Slug         string     `yaml:"slug,omitempty"`
+	Tags         []string   `yaml:"tags,omitempty"`
+	Authors      []string   `yaml:"authors,omitempty"`
+	Status       string     `yaml:"status,omitempty"`
+	FeatureImage string     `yaml:"feature_image,omitempty"`
+	PublishedAt  *time.Time `yaml:"published_at,omitempty"`
+	ID           string     `yaml:"id,omitempty"`
+	UpdatedAt    *time.Time `yaml:"updated_at,omitempty"`
+}
+
+// Document is a Markdown file: its front matter and Markdown body.
+type Document struct {
+	FrontMatter FrontMatter
+	Body        string
+}
+
+// Parse splits b into front matter, between lines of "---" at the start of
+// the file, and body. Files without front matter are all body.
+func Parse(b []byte) (*Document, error) {
+	text := strings.TrimPrefix(string(b), "\ufeff")
+	text = strings.Replace(text, "\r\n", "\n", -1)
+
+	doc := &Document{}
+	if !strings.HasPrefix(text, frontMatterDelimiter+"\n") {
+		doc.Body = text
+		return doc, nil
+	}
+
+	rest := text[len(frontMatterDelimiter)+1:]
+	var yamlText string
+	switch end := strings.Index("\n"+rest, "\n"+frontMatterDelimiter+"\n"); {
+	case end >= 0:
+		yamlText, doc.Body = rest[:end], rest[end+len(frontMatterDelimiter)+1:]
+	case strings.HasSuffix("\n"+rest, "\n"+frontMatterDelimiter):
+		yamlText = strings.TrimSuffix(rest, frontMatterDelimiter)
+	default:
+		return nil, fmt.Errorf("markdown: front matter is not closed by %q", frontMatterDelimiter)
+	}
+
+	if err := yaml.Unmarshal([]byte(yamlText), &doc.FrontMatter); err != nil {
+		return nil, fmt.Errorf("markdown: invalid front matter: %v", err)
+	}
+	doc.Body = strings.TrimLeft(doc.Body, "\n")
+	return doc, nil
+}
+
+// Post returns the post of d, ready for Posts.Create, or for Posts.Update
+// when the front matter has an id and updated_at. The status defaults to
+// draft, and scheduled posts need a published_at.
+func (d *Document) Post() (*ghost.Post, error) {
+	fm := d.FrontMatter
+	if strings.TrimSpace(fm.Title) == "" {
+		return nil, ErrNoTitle
+	}
+
+	status := fm.Status
+	switch status {
+	case "":
+		status = StatusDraft
+	case StatusDraft, StatusPublished:
+	case StatusScheduled:
+		if fm.PublishedAt == nil {
+			return nil, errors.New("markdown: scheduled post has no published_at")
+		}
+	default:
+		return nil, fmt.Errorf("markdown: unknown status %q", fm.Status)
+	}
+
+	md, err := mobiledoc.NewBuilder().Card(mobiledoc.MarkdownCard{Markdown: d.Body}).Document()
+	if err != nil {
+		return nil, err
+	}
+	content, err := md.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	post := &ghost.Post{
+		Title:     ghost.String(fm.Title),
+		Mobiledoc: ghost.String(content),
+		Status:    ghost.String(status),
+	}
+	if fm.ID != "" {
+		post.ID = ghost.String(fm.ID)
+	}
+	if fm.UpdatedAt != nil {
+		updatedAt := fm.UpdatedAt.UTC()
+		post.UpdatedAt = &updatedAt
+	}
+	if fm.Slug != "" {
+		post.Slug = ghost.String(fm.Slug)
+	}
+	if fm.FeatureImage != "" {
+		post.FeatureImage = ghost.String(fm.FeatureImage)
+	}
+	if fm.PublishedAt != nil {
+		publishedAt := fm.PublishedAt.UTC()
+		post.PublishedAt = &publishedAt
+	}
+	for _, name := range fm.Tags {
+		post.Tags = append(post.Tags, &ghost.Tag{Name: ghost.String(name)})
+	}
+	for _, author := range fm.Authors {
+		if strings.Contains(author, "@") {
+			post.Authors = append(post.Authors, &ghost.Author{Email: ghost.String(author)})
+		} else {
+			post.Authors = append(post.Authors, &ghost.Author{Slug: ghost.String(author)})
+		}
+	}
+	return post, nil
+}
+
+// Convert parses the Markdown file b and returns its post.
+func Convert(b []byte) (*ghost.Post, error) {
+	doc, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Post()
+}
+
+// FromPost returns the Markdown file of post, whose content must be a single
+// markdown card as created by Post, or else ErrNotMarkdown. Posts with lexical
+// content, or with html but no mobiledoc, were written in the editor and are
+// not Markdown either. Authors are given by email address where known.
+func FromPost(post *ghost.Post) (*Document, error) {
+	var body string
+	switch {
+	case post.Lexical != nil && *post.Lexical != "":
+		return nil, ErrNotMarkdown
+	case post.Mobiledoc == nil || *post.Mobiledoc == "":
+		if post.HTML != nil && *post.HTML != "" {
+			return nil, ErrNotMarkdown
+		}
+	default:
+		md, err := mobiledoc.Parse(*post.Mobiledoc)
+		if err != nil {
+			return nil, err
+		}
+		switch len(md.Sections) {
+		case 0:
+		case 1:
+			section, ok := md.Sections[0].(*mobiledoc.CardSection)
+			if !ok || section.Card.Name != "markdown" && section.Card.Name != "card-markdown" {
+				return nil, ErrNotMarkdown
+			}
+			var card mobiledoc.MarkdownCard
+			if err := section.Card.Decode(&card); err != nil {
+				return nil, err
+			}
+			body = card.Markdown
+		default:
+			return nil, ErrNotMarkdown
+		}
+	}
+
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	utc := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		u := t.UTC()
+		return &u
+	}
+
+	fm := FrontMatter{
+		Title:        deref(post.Title),
+		Slug:         deref(post.Slug),
+		Status:       deref(post.Status),
+		FeatureImage: deref(post.FeatureImage),
+		PublishedAt:  utc(post.PublishedAt),
+		ID:           deref(post.ID),
+		UpdatedAt:    utc(post.UpdatedAt),
+	}
+	for _, tag := range post.Tags {
+		if name := deref(tag.Name); name != "" {
+			fm.Tags = append(fm.Tags, name)
+		}
+	}
+	for _, author := range post.Authors {
+		if email := deref(author.Email); email != "" {
+			fm.Authors = append(fm.Authors, email)
+		} else if slug := deref(author.Slug); slug != "" {
+			fm.Authors = append(fm.Authors, slug)
+		}
+	}
+	return &Document{FrontMatter: fm, Body: body}, nil
+}
+
+// Marshal encodes d as a Markdown file with front matter, as read by Parse.
+func (d *Document) Marshal() ([]byte, error) {
+	fm, err := yaml.Marshal(d.FrontMatter)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString(frontMatterDelimiter + "\n")
+	b.Write(fm)
+	b.WriteString(frontMatterDelimiter + "\n\n")
+	b.WriteString(d.Body)
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+	"github.com/stretchr/testify/require"
+)
+
+const testPost = `---
+title: Hello, world
+slug: hello
+tags: [News, "#internal"]
+authors:
+  - jane@example.com
+  - joe
+status: published
+feature_image: /content/images/hello.jpg
+published_at: 2020-03-01T10:00:00+01:00
+---
+
+# Hello
+
+Some *Markdown* with <b>html</b>.
+`
+
+func TestConvert(t *testing.T) {
+	post, err := Convert([]byte(testPost))
+	require.NoError(t, err)
+	require.Equal(t, "Hello, world", *post.Title)
+	require.Equal(t, "hello", *post.Slug)
+	require.Equal(t, "published", *post.Status)
+	require.Equal(t, "/content/images/hello.jpg", *post.FeatureImage)
+	require.Equal(t, time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC), *post.PublishedAt)
+	require.Len(t, post.Tags, 2)
+	require.Equal(t, "#internal", *post.Tags[1].Name)
+	require.Equal(t, "jane@example.com", *post.Authors[0].Email)
+	require.Equal(t, "joe", *post.Authors[1].Slug)
+
+	require.Equal(t, `{"version":"0.3.1","atoms":[],"cards":[["markdown",{"markdown":"# Hello\n\nSome *Markdown* with <b>html</b>.\n"}]],"markups":[],"sections":[[10,0]]}`, *post.Mobiledoc)
+	doc, err := mobiledoc.Parse(*post.Mobiledoc)
+	require.NoError(t, err)
+	r := &mobiledoc.Renderer{Markdown: func(md string) (string, error) { return "<h1>Hello</h1>", nil }}
+	html, err := r.Render(doc)
+	require.NoError(t, err)
+	require.Equal(t, "<!--kg-card-begin: markdown--><h1>Hello</h1><!--kg-card-end: markdown-->", html)
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte("---\r\ntitle: Draft\r\n---\r\nBody\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, "Draft", doc.FrontMatter.Title)
+	require.Equal(t, "Body\n", doc.Body)
+	post, err := doc.Post()
+	require.NoError(t, err)
+	require.Equal(t, "draft", *post.Status)
+	require.Nil(t, post.Slug)
+	require.Nil(t, post.PublishedAt)
+
+	doc, err = Parse([]byte("No front matter\n---\n"))
+	require.NoError(t, err)
+	require.Equal(t, "No front matter\n---\n", doc.Body)
+	_, err = doc.Post()
+	require.Equal(t, ErrNoTitle, err)
+
+	_, err = Parse([]byte("---\ntitle: Open\n"))
+	require.Error(t, err)
+	_, err = Convert([]byte("---\ntitle: Later\nstatus: scheduled\n---\n"))
+	require.Error(t, err)
+	_, err = Convert([]byte("---\ntitle: Odd\nstatus: archived\n---\n"))
+	require.Error(t, err)
+}
+
+func TestFromPost(t *testing.T) {
+	post, err := Convert([]byte(testPost))
+	require.NoError(t, err)
+	updatedAt := time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+	post.ID, post.UpdatedAt = ghost.String("p1"), &updatedAt
+	post.Authors[1].Name = ghost.String("Joe")
+
+	doc, err := FromPost(post)
+	require.NoError(t, err)
+	b, err := doc.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, `---
+title: Hello, world
+slug: hello
+tags:
+- News
+- '#internal'
+authors:
+- jane@example.com
+- joe
+status: published
+feature_image: /content/images/hello.jpg
+published_at: 2020-03-01T09:00:00Z
+id: p1
+updated_at: 2020-03-02T00:00:00Z
+---
+
+# Hello
+
+Some *Markdown* with <b>html</b>.
+`, string(b))
+
+	again, err := Convert(b)
+	require.NoError(t, err)
+	require.Equal(t, post.Mobiledoc, again.Mobiledoc)
+	require.Equal(t, "p1", *again.ID)
+	require.True(t, updatedAt.Equal(*again.UpdatedAt))
+
+	_, err = FromPost(&ghost.Post{Mobiledoc: ghost.String(`{"version":"0.3.1","sections":[[1,"p",[]]]}`)})
+	require.Equal(t, ErrNotMarkdown, err)
+	_, err = FromPost(&ghost.Post{Lexical: ghost.String(`{"root":{"children":[],"type":"root","version":1}}`)})
+	require.Equal(t, ErrNotMarkdown, err)
+	_, err = FromPost(&ghost.Post{HTML: ghost.String("<p>Written in the editor</p>")})
+	require.Equal(t, ErrNotMarkdown, err)
+}
@@ -0,0 +1,520 @@
+// Package mdsync mirrors a directory of Markdown files, typically a git
+// checkout, to the posts of a Ghost site and back.
+//
+// Files are converted with package markdown, and the id and updated_at of
+// their post are written into their front matter. A state file, StateFile,
+// records each post as last synced, and is meant to be committed along with
+// the files: it tells a file removed from the directory from a post that is
+// new in Ghost, and a file changed locally from one that was only pulled.
+// When a file and its post both changed since they were last synced, the
+// plan reports a conflict and leaves both alone.
+package mdsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/markdown"
+)
+
+// StateFile is the name of the state file in the synced directory.
+const StateFile = ".ghost-sync.json"
+
+// RemoveMode is what becomes of posts whose files were removed.
+type RemoveMode string
+
+// Remove modes.
+const (
+	// Unpublish turns the post into a draft, which is no longer synced.
+	Unpublish RemoveMode = "unpublish"
+	// Delete deletes the post.
+	Delete RemoveMode = "delete"
+)
+
+// Options configure a sync.
+type Options struct {
+	// Filter limits the posts synced, e.g. "tag:docs". Posts outside it
+	// are still synced when a file has their id.
+	Filter string
+	// Remove is what becomes of posts whose files were removed. Defaults to
+	// Unpublish.
+	Remove RemoveMode
+}
+
+// ActionKind is the kind of an action of a plan.
+type ActionKind string
+
+// Action kinds.
+const (
+	// CreatePost creates the post of a file without an id.
+	CreatePost ActionKind = "create"
+	// PushPost updates a post from its changed file.
+	PushPost ActionKind = "push"
+	// PullFile writes the file of a post that is new or changed in Ghost.
+	PullFile ActionKind = "pull"
+	// DeleteFile removes the file of a post deleted in Ghost.
+	DeleteFile ActionKind = "delete-file"
+	// DeletePost deletes the post of a removed file.
+	DeletePost ActionKind = "delete-post"
+	// UnpublishPost unpublishes the post of a removed file.
+	UnpublishPost ActionKind = "unpublish"
+	// Conflict is a file and post that cannot be synced, such as when both
+	// changed, and are left alone.
+	Conflict ActionKind = "conflict"
+	// Skip is a file or post that cannot be synced at all, such as a post
+	// written in the Ghost editor rather than in Markdown.
+	Skip ActionKind = "skip"
+)
+
+// Action is a step of a plan. Path is relative to the synced directory,
+// with forward slashes.
+type Action struct {
+	Kind   ActionKind
+	Path   string
+	PostID string
+	Reason string
+
+	doc  *markdown.Document
+	post *ghost.Post
+}
+
+func (a *Action) String() string {
+	s := fmt.Sprintf("%-11s %s", a.Kind, a.Path)
+	if a.Path == "" {
+		s = fmt.Sprintf("%-11s post %s", a.Kind, a.PostID)
+	} else if a.PostID != "" {
+		s += " (" + a.PostID + ")"
+	}
+	if a.Reason != "" {
+		s += ": " + a.Reason
+	}
+	return s
+}
+
+// Plan is the actions that sync a directory with a site. Printing a plan
+// without applying it is a dry run.
+type Plan struct {
+	Dir     string
+	Actions []*Action
+
+	remove RemoveMode
+	state  *state
+}
+
+// String lists the actions of p, one per line.
+func (p *Plan) String() string {
+	if len(p.Actions) == 0 {
+		return "nothing to sync\n"
+	}
+	var sb strings.Builder
+	for _, a := range p.Actions {
+		sb.WriteString(a.String() + "\n")
+	}
+	return sb.String()
+}
+
+// Conflicts returns the conflicts of p.
+func (p *Plan) Conflicts() []*Action {
+	var conflicts []*Action
+	for _, a := range p.Actions {
+		if a.Kind == Conflict {
+			conflicts = append(conflicts, a)
+		}
+	}
+	return conflicts
+}
+
+// state is the content of the state file: the posts as last synced.
+type state struct {
+	Posts map[string]*syncedPost `json:"posts"`
+}
+
+// syncedPost is a post as last synced: its file, the hash of the file's
+// content and the post's updated_at. Unpublished posts are no longer synced.
+type syncedPost struct {
+	Path        string    `json:"path,omitempty"`
+	Hash        string    `json:"hash,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Unpublished bool      `json:"unpublished,omitempty"`
+}
+
+func loadState(dir string) (*state, error) {
+	s := &state{Posts: make(map[string]*syncedPost)}
+	b, err := ioutil.ReadFile(filepath.Join(dir, StateFile))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("mdsync: invalid %s: %v", StateFile, err)
+	}
+	if s.Posts == nil {
+		s.Posts = make(map[string]*syncedPost)
+	}
+	return s, nil
+}
+
+func (s *state) save(dir string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, StateFile), append(b, '\n'), 0644)
+}
+
+func hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// localFile is a Markdown file of the synced directory.
+type localFile struct {
+	path string
+	data []byte
+	doc  *markdown.Document
+}
+
+// readFiles reads the Markdown files under dir, skipping hidden directories
+// such as .git.
+func readFiles(dir string) ([]*localFile, []*Action, error) {
+	var files []*localFile
+	var skipped []*Action
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		doc, err := markdown.Parse(data)
+		if err != nil {
+			skipped = append(skipped, &Action{Kind: Skip, Path: rel, Reason: err.Error()})
+			return nil
+		}
+		files = append(files, &localFile{path: rel, data: data, doc: doc})
+		return nil
+	})
+	return files, skipped, err
+}
+
+// listPosts fetches the posts matching filter, by id.
+func listPosts(client *ghost.AdminClient, filter string) (map[string]*ghost.Post, []*ghost.Post, error) {
+	byID := make(map[string]*ghost.Post)
+	var posts []*ghost.Post
+	params := &ghost.ListParams{Filter: filter, Limit: 100, Page: 1}
+	for {
+		page, resp, err := client.Posts.List(params)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, post := range page.Posts {
+			if post.ID != nil {
+				byID[*post.ID] = post
+				posts = append(posts, post)
+			}
+		}
+		if resp.Meta == nil || resp.Meta.Pagination == nil || resp.Meta.Pagination.Next == nil {
+			return byID, posts, nil
+		}
+		params.Page = *resp.Meta.Pagination.Next
+	}
+}
+
+// getPost fetches a post outside the filter, returning nil if it was deleted.
+func getPost(client *ghost.AdminClient, id string) (*ghost.Post, error) {
+	post, _, err := client.Posts.Get(id)
+	if errResp, ok := err.(*ghost.ErrorResponse); ok && errResp.Response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	return post, err
+}
+
+// render returns the file of post, or an error if post is not Markdown.
+func render(post *ghost.Post) (*markdown.Document, []byte, error) {
+	doc, err := markdown.FromPost(post)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := doc.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, data, nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	return a != nil && b != nil && a.Equal(*b)
+}
+
+// NewPlan compares the Markdown files under dir with the posts of the site,
+// returning the actions that sync them. Nothing is changed until the plan is
+// applied.
+func NewPlan(client *ghost.AdminClient, dir string, opts *Options) (*Plan, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	plan := &Plan{Dir: dir, remove: opts.Remove}
+	if plan.remove == "" {
+		plan.remove = Unpublish
+	}
+	if plan.remove != Unpublish && plan.remove != Delete {
+		return nil, fmt.Errorf("mdsync: unknown remove mode %q", plan.remove)
+	}
+
+	var err error
+	if plan.state, err = loadState(dir); err != nil {
+		return nil, err
+	}
+	files, skipped, err := readFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	plan.Actions = append(plan.Actions, skipped...)
+	posts, postList, err := listPosts(client, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	seen := make(map[string]string)
+	for _, f := range files {
+		paths[f.path] = true
+		id := f.doc.FrontMatter.ID
+		if id == "" {
+			if _, err := f.doc.Post(); err != nil {
+				plan.add(&Action{Kind: Skip, Path: f.path, Reason: err.Error()})
+			} else {
+				plan.add(&Action{Kind: CreatePost, Path: f.path, doc: f.doc})
+			}
+			continue
+		}
+		if other, ok := seen[id]; ok {
+			plan.add(&Action{Kind: Conflict, Path: f.path, PostID: id, Reason: "same id as " + other})
+			continue
+		}
+		seen[id] = f.path
+
+		post, ok := posts[id]
+		if !ok {
+			if post, err = getPost(client, id); err != nil {
+				return nil, err
+			}
+		}
+		if err := plan.compare(f, post); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, post := range postList {
+		id := *post.ID
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		synced := plan.state.Posts[id]
+		switch {
+		case synced != nil && synced.Unpublished:
+		case synced != nil && !sameTime(&synced.UpdatedAt, post.UpdatedAt):
+			plan.add(&Action{Kind: Conflict, Path: synced.Path, PostID: id, Reason: "file removed, but post changed in Ghost"})
+		case synced != nil && plan.remove == Delete:
+			plan.add(&Action{Kind: DeletePost, Path: synced.Path, PostID: id, post: post})
+		case synced != nil:
+			plan.add(&Action{Kind: UnpublishPost, Path: synced.Path, PostID: id, post: post})
+		default:
+			doc, _, err := render(post)
+			if err != nil {
+				plan.add(&Action{Kind: Skip, PostID: id, Reason: err.Error()})
+				continue
+			}
+			path := doc.FrontMatter.Slug + ".md"
+			if doc.FrontMatter.Slug == "" || paths[path] {
+				path = id + ".md"
+			}
+			paths[path] = true
+			plan.add(&Action{Kind: PullFile, Path: path, PostID: id, post: post})
+		}
+	}
+	return plan, nil
+}
+
+func (p *Plan) add(a *Action) {
+	p.Actions = append(p.Actions, a)
+}
+
+// compare plans the sync of a file with the post of its id, nil if the post
+// was deleted.
+func (p *Plan) compare(f *localFile, post *ghost.Post) error {
+	id := f.doc.FrontMatter.ID
+	synced := p.state.Posts[id]
+	if synced != nil && synced.Unpublished {
+		// the file was restored, so the post is synced again.
+		synced = nil
+	}
+
+	if post == nil {
+		if synced != nil && synced.Hash == hash(f.data) {
+			p.add(&Action{Kind: DeleteFile, Path: f.path, PostID: id})
+		} else {
+			p.add(&Action{Kind: Conflict, Path: f.path, PostID: id, Reason: "post deleted in Ghost, but file changed"})
+		}
+		return nil
+	}
+
+	_, remoteData, renderErr := render(post)
+	if renderErr == nil && post.UpdatedAt != nil && string(remoteData) == string(f.data) {
+		// in sync already, if not recorded as such.
+		p.state.Posts[id] = &syncedPost{Path: f.path, Hash: hash(f.data), UpdatedAt: *post.UpdatedAt}
+		return nil
+	}
+
+	// without a record of the last sync, a file differing from its post
+	// counts as changed.
+	remoteChanged := !sameTime(f.doc.FrontMatter.UpdatedAt, post.UpdatedAt)
+	localChanged := synced == nil || synced.Hash != hash(f.data)
+
+	switch {
+	case remoteChanged && renderErr != nil:
+		p.add(&Action{Kind: Conflict, Path: f.path, PostID: id, Reason: "post changed in Ghost and " + renderErr.Error()})
+	case remoteChanged && localChanged:
+		p.add(&Action{Kind: Conflict, Path: f.path, PostID: id, Reason: "changed both locally and in Ghost"})
+	case remoteChanged:
+		p.add(&Action{Kind: PullFile, Path: f.path, PostID: id, post: post})
+	case localChanged:
+		if _, err := f.doc.Post(); err != nil {
+			p.add(&Action{Kind: Skip, Path: f.path, PostID: id, Reason: err.Error()})
+		} else {
+			p.add(&Action{Kind: PushPost, Path: f.path, PostID: id, doc: f.doc})
+		}
+	}
+	return nil
+}
+
+// Apply carries out the plan, leaving conflicts and skipped files and posts
+// alone, and saves the state. It stops at the first failing action; the
+// state then records the actions carried out.
+func (p *Plan) Apply(client *ghost.AdminClient) (err error) {
+	defer func() {
+		if saveErr := p.state.save(p.Dir); err == nil {
+			err = saveErr
+		}
+	}()
+
+	for _, a := range p.Actions {
+		if err := p.apply(client, a); err != nil {
+			return fmt.Errorf("mdsync: %s: %v", a, err)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) apply(client *ghost.AdminClient, a *Action) error {
+	path := filepath.Join(p.Dir, filepath.FromSlash(a.Path))
+	switch a.Kind {
+	case CreatePost, PushPost:
+		post, err := a.doc.Post()
+		if err != nil {
+			return err
+		}
+		if a.Kind == CreatePost {
+			post, _, err = client.Posts.Create(post)
+		} else {
+			post, _, err = client.Posts.Update(post, clearOmitted(post)...)
+		}
+		if err != nil {
+			return err
+		}
+		a.doc.FrontMatter.ID = *post.ID
+		a.doc.FrontMatter.UpdatedAt = post.UpdatedAt
+		a.PostID = *post.ID
+		return p.write(path, a.Path, a.doc, post)
+	case PullFile:
+		doc, _, err := render(a.post)
+		if err != nil {
+			return err
+		}
+		return p.write(path, a.Path, doc, a.post)
+	case DeleteFile:
+		delete(p.state.Posts, a.PostID)
+		return os.Remove(path)
+	case DeletePost:
+		if _, err := client.Posts.Delete(a.PostID); err != nil {
+			return err
+		}
+		delete(p.state.Posts, a.PostID)
+	case UnpublishPost:
+		post, _, err := client.Posts.Update(&ghost.Post{ID: a.post.ID, UpdatedAt: a.post.UpdatedAt, Status: ghost.String("draft")})
+		if err != nil {
+			return err
+		}
+		p.state.Posts[a.PostID] = &syncedPost{UpdatedAt: *post.UpdatedAt, Unpublished: true}
+	}
+	return nil
+}
+
+// clearOmitted makes the tags and feature image left out of the front matter
+// of a pushed file clear those of its post, rather than leave them unchanged,
+// returning the fields to send as null. Authors left out are left unchanged,
+// as Ghost requires at least one.
+func clearOmitted(post *ghost.Post) []string {
+	if post.Tags == nil {
+		post.Tags = []*ghost.Tag{}
+	}
+	if post.FeatureImage == nil {
+		return []string{"feature_image"}
+	}
+	return nil
+}
+
+// write writes the file of doc and records it as synced with post.
+func (p *Plan) write(path, rel string, doc *markdown.Document, post *ghost.Post) error {
+	data, err := doc.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	if post.UpdatedAt == nil {
+		return fmt.Errorf("post %s has no updated_at", *post.ID)
+	}
+	p.state.Posts[*post.ID] = &syncedPost{Path: rel, Hash: hash(data), UpdatedAt: *post.UpdatedAt}
+	return nil
+}
+
+// Sync plans and applies the sync of dir with the site.
+func Sync(client *ghost.AdminClient, dir string, opts *Options) (*Plan, error) {
+	plan, err := NewPlan(client, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return plan, plan.Apply(client)
+}
@@ -0,0 +1,306 @@
+package mdsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/pubbit-co/go-ghost/markdown"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGhost keeps posts in memory, refusing updates of stale posts as Ghost
+// does.
+type fakeGhost struct {
+	mu    sync.Mutex
+	posts map[string]*ghost.Post
+	order []string
+	clock time.Time
+	next  int
+}
+
+func (g *fakeGhost) add(post *ghost.Post) *ghost.Post {
+	if post.ID == nil {
+		g.next++
+		post.ID = ghost.String(fmt.Sprintf("post%d", g.next))
+		g.order = append(g.order, *post.ID)
+	}
+	g.clock = g.clock.Add(time.Minute)
+	updatedAt := g.clock
+	post.UpdatedAt = &updatedAt
+	g.posts[*post.ID] = post
+	return post
+}
+
+func (g *fakeGhost) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, ghost.BaseAdminPath+"posts"), "/")
+	reply := func(posts ...*ghost.Post) {
+		json.NewEncoder(w).Encode(&ghost.PostsResponse{Posts: posts})
+	}
+	var body struct{ Posts []*ghost.Post }
+
+	switch {
+	case r.Method == "GET" && id == "":
+		var posts []*ghost.Post
+		for _, id := range g.order {
+			if post, ok := g.posts[id]; ok {
+				posts = append(posts, post)
+			}
+		}
+		reply(posts...)
+	case r.Method == "GET":
+		post, ok := g.posts[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		reply(post)
+	case r.Method == "POST":
+		json.NewDecoder(r.Body).Decode(&body)
+		reply(g.add(body.Posts[0]))
+	case r.Method == "PUT":
+		var raw struct{ Posts []json.RawMessage }
+		json.NewDecoder(r.Body).Decode(&raw)
+		post, ok := g.posts[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		// fields left out of the update are unchanged, and null ones
+		// cleared.
+		update := *post
+		json.Unmarshal(raw.Posts[0], &update)
+		if !post.UpdatedAt.Equal(*update.UpdatedAt) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if update.Authors != nil && len(update.Authors) == 0 {
+			http.Error(w, "At least one author is required", http.StatusUnprocessableEntity)
+			return
+		}
+		reply(g.add(&update))
+	case r.Method == "DELETE":
+		delete(g.posts, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func setup(t *testing.T) (*ghost.AdminClient, *fakeGhost, string, func()) {
+	g := &fakeGhost{posts: make(map[string]*ghost.Post), clock: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mux := http.NewServeMux()
+	mux.Handle(ghost.BaseAdminPath+"posts", g)
+	mux.Handle(ghost.BaseAdminPath+"posts/", g)
+	server := httptest.NewServer(mux)
+
+	client, err := ghost.NewAdminClient(server.URL, &http.Client{})
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "mdsync")
+	require.NoError(t, err)
+	return client, g, dir, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func readFile(t *testing.T, dir, name string) *markdown.Document {
+	b, err := ioutil.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	doc, err := markdown.Parse(b)
+	require.NoError(t, err)
+	return doc
+}
+
+func kinds(plan *Plan) []string {
+	var kinds []string
+	for _, a := range plan.Actions {
+		kinds = append(kinds, string(a.Kind)+" "+a.Path)
+	}
+	return kinds
+}
+
+func TestSync(t *testing.T) {
+	client, g, dir, teardown := setup(t)
+	defer teardown()
+
+	remote, err := markdown.Convert([]byte("---\ntitle: From Ghost\nslug: from-ghost\n---\nWritten in Ghost.\n"))
+	require.NoError(t, err)
+	g.add(remote)
+	g.add(&ghost.Post{Title: ghost.String("Rich"), Mobiledoc: ghost.String(`{"version":"0.3.1","atoms":[],"cards":[],"markups":[],"sections":[[1,"p",[]]]}`)})
+	writeFile(t, dir, "docs/local.md", "---\ntitle: Local\ntags: [Docs]\n---\nWritten in git.\n")
+	writeFile(t, dir, ".git/ignored.md", "not a post")
+
+	// a dry run changes nothing.
+	plan, err := NewPlan(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"create docs/local.md", "pull from-ghost.md", "skip "}, kinds(plan))
+	require.Contains(t, plan.String(), "skip        post post2: markdown: post content is not a markdown card")
+	_, err = os.Stat(filepath.Join(dir, "from-ghost.md"))
+	require.True(t, os.IsNotExist(err))
+	require.Len(t, g.posts, 2)
+
+	require.NoError(t, plan.Apply(client))
+	local := readFile(t, dir, "docs/local.md")
+	require.Equal(t, "post3", local.FrontMatter.ID)
+	require.Equal(t, "Docs", *g.posts["post3"].Tags[0].Name)
+	pulled := readFile(t, dir, "from-ghost.md")
+	require.Equal(t, "post1", pulled.FrontMatter.ID)
+	require.Equal(t, "Written in Ghost.\n", pulled.Body)
+
+	plan, err = NewPlan(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"skip "}, kinds(plan))
+
+	// changes on either side are pushed and pulled.
+	local.Body = "Edited in git.\n"
+	b, _ := local.Marshal()
+	writeFile(t, dir, "docs/local.md", string(b))
+	edited, err := markdown.Convert([]byte("---\ntitle: From Ghost\nslug: from-ghost\n---\nEdited in Ghost.\n"))
+	require.NoError(t, err)
+	edited.ID = ghost.String("post1")
+	g.add(edited)
+
+	plan, err = Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"push docs/local.md", "pull from-ghost.md", "skip "}, kinds(plan))
+	require.Contains(t, *g.posts["post3"].Mobiledoc, "Edited in git.")
+	require.Equal(t, "Edited in Ghost.\n", readFile(t, dir, "from-ghost.md").Body)
+	require.True(t, g.posts["post3"].UpdatedAt.Equal(*readFile(t, dir, "docs/local.md").FrontMatter.UpdatedAt))
+
+	// changes on both sides conflict, and are left alone.
+	local = readFile(t, dir, "docs/local.md")
+	local.Body = "Conflicting.\n"
+	b, _ = local.Marshal()
+	writeFile(t, dir, "docs/local.md", string(b))
+	g.add(g.posts["post3"])
+
+	plan, err = Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Len(t, plan.Conflicts(), 1)
+	require.Equal(t, "changed both locally and in Ghost", plan.Conflicts()[0].Reason)
+	require.Contains(t, *g.posts["post3"].Mobiledoc, "Edited in git.")
+	require.Equal(t, "Conflicting.\n", readFile(t, dir, "docs/local.md").Body)
+}
+
+func TestSync_ClearFields(t *testing.T) {
+	client, g, dir, teardown := setup(t)
+	defer teardown()
+
+	writeFile(t, dir, "a.md", "---\ntitle: A\ntags: [News]\nfeature_image: /a.jpg\n---\nA\n")
+	_, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Len(t, g.posts["post1"].Tags, 1)
+
+	// fields removed from the file are cleared in Ghost.
+	doc := readFile(t, dir, "a.md")
+	doc.FrontMatter.Tags, doc.FrontMatter.FeatureImage = nil, ""
+	b, _ := doc.Marshal()
+	writeFile(t, dir, "a.md", string(b))
+
+	plan, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"push a.md"}, kinds(plan))
+	require.Empty(t, g.posts["post1"].Tags)
+	require.Nil(t, g.posts["post1"].FeatureImage)
+
+	plan, err = NewPlan(client, dir, nil)
+	require.NoError(t, err)
+	require.Empty(t, plan.Actions)
+}
+
+func TestSync_OmittedAuthors(t *testing.T) {
+	client, g, dir, teardown := setup(t)
+	defer teardown()
+
+	writeFile(t, dir, "a.md", "---\ntitle: A\n---\nA\n")
+	_, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	g.posts["post1"].Authors = []*ghost.Author{{Slug: ghost.String("jane")}}
+
+	// files without authors keep those of their post, as Ghost refuses
+	// posts without any.
+	doc := readFile(t, dir, "a.md")
+	doc.Body = "Edited.\n"
+	b, _ := doc.Marshal()
+	writeFile(t, dir, "a.md", string(b))
+
+	plan, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"push a.md"}, kinds(plan))
+	require.Contains(t, *g.posts["post1"].Mobiledoc, "Edited.")
+	require.Equal(t, "jane", *g.posts["post1"].Authors[0].Slug)
+}
+
+func TestSync_Lexical(t *testing.T) {
+	client, g, dir, teardown := setup(t)
+	defer teardown()
+
+	// posts written in the Ghost 5 editor have no mobiledoc, and are not
+	// pulled as empty files.
+	g.add(&ghost.Post{
+		Title:   ghost.String("Editor"),
+		Slug:    ghost.String("editor"),
+		Lexical: ghost.String(`{"root":{"children":[{"children":[{"text":"Rich","type":"text","version":1}],"type":"paragraph","version":1}],"type":"root","version":1}}`),
+	})
+
+	plan, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"skip "}, kinds(plan))
+	require.Equal(t, markdown.ErrNotMarkdown.Error(), plan.Actions[0].Reason)
+	_, err = os.Stat(filepath.Join(dir, "editor.md"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSync_Remove(t *testing.T) {
+	client, g, dir, teardown := setup(t)
+	defer teardown()
+
+	writeFile(t, dir, "a.md", "---\ntitle: A\nstatus: published\n---\nA\n")
+	writeFile(t, dir, "b.md", "---\ntitle: B\n---\nB\n")
+	writeFile(t, dir, "c.md", "---\ntitle: C\n---\nC\n")
+	_, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Len(t, g.posts, 3)
+
+	// removed files unpublish their posts, which are then left alone.
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.md")))
+	plan, err := Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"unpublish a.md"}, kinds(plan))
+	require.Equal(t, "draft", *g.posts["post1"].Status)
+	plan, err = NewPlan(client, dir, nil)
+	require.NoError(t, err)
+	require.Empty(t, plan.Actions)
+	require.Equal(t, "nothing to sync\n", plan.String())
+
+	// or delete them.
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.md")))
+	plan, err = Sync(client, dir, &Options{Remove: Delete})
+	require.NoError(t, err)
+	require.Equal(t, []string{"delete-post b.md"}, kinds(plan))
+	require.NotContains(t, g.posts, "post2")
+
+	// posts deleted in Ghost remove their files.
+	delete(g.posts, "post3")
+	plan, err = Sync(client, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"delete-file c.md"}, kinds(plan))
+	_, err = os.Stat(filepath.Join(dir, "c.md"))
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,440 @@
+// Package merge consolidates several Ghost database exports into one, ready
+// to import into a single site, along with the redirects keeping the old
+// URLs working.
+package merge
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// SlugStrategy decides what happens to a post whose slug is already taken by
+// a post of an earlier source.
+type SlugStrategy int
+
+const (
+	// SuffixSlug appends -2, -3, etc. to the slug until it is free.
+	SuffixSlug SlugStrategy = iota
+	// PrefixSourceSlug prefixes the slug with the source name, then suffixes
+	// it should it still be taken.
+	PrefixSourceSlug
+	// SkipDuplicate leaves the post out of the merged export.
+	SkipDuplicate
+)
+
+// Source is a single export to merge.
+type Source struct {
+	// Name identifies the source, e.g. in prefixed slugs.
+	Name string
+	DB   *ghost.Database
+
+	// PathPrefix, if set, is the path under which the old URLs of this source
+	// are forwarded to the merged site, e.g. "/travel" when the old domain
+	// redirects there. Redirects are then emitted for every post, tag and
+	// author of the source. Without it, redirects are only emitted for
+	// renamed items whose old path is not served by another merged item.
+	PathPrefix string
+}
+
+// Options configure Merge.
+type Options struct {
+	SlugStrategy SlugStrategy
+	// NewID generates ids for rows whose id is already taken. Defaults to
+	// random 24 character hex ids, like Ghost's own.
+	NewID func() string
+}
+
+// Result is the merged export along with the redirects for it.
+type Result struct {
+	DB        *ghost.Database
+	Redirects []*ghost.Redirect
+	// Skipped are the ids, within their source, of posts left out by
+	// SkipDuplicate, keyed by source name.
+	Skipped map[string][]string
+}
+
+// Merge merges the sources in order. Tags are deduplicated by slug, staff
+// users and members by email and roles by name, with earlier sources taking
+// precedence. Colliding ids are remapped and every reference to them updated.
+// Meta and settings are taken from the first source. Tables without a typed
+// field are taken from the first source only, as their references are not
+// known, except for the member_id of members_* tables.
+func Merge(sources []*Source, opts *Options) (*Result, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("nothing to merge")
+	}
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.NewID == nil {
+		o.NewID = randomID
+	}
+	m := &merger{
+		opts:      &o,
+		data:      &ghost.DatabaseData{},
+		ids:       make(map[string]bool),
+		postSlugs: make(map[string]bool),
+		userSlugs: make(map[string]bool),
+		tags:      make(map[string]string),
+		users:     make(map[string]string),
+		members:   make(map[string]string),
+		roles:     make(map[string]string),
+		joins:     make(map[string]bool),
+		result:    &Result{Skipped: make(map[string][]string)},
+	}
+
+	first := sources[0].DB
+	m.result.DB = &ghost.Database{Meta: first.Meta, Data: m.data}
+	if first.Data != nil {
+		m.data.Settings = first.Data.Settings
+	}
+
+	for i, source := range sources {
+		if source.DB == nil || source.DB.Data == nil {
+			continue
+		}
+		ids := m.source(source)
+		if i == 0 {
+			extra, err := remapMembers(source.DB.Data.Extra, ids)
+			if err != nil {
+				return nil, err
+			}
+			m.data.Extra = extra
+		}
+	}
+	m.redirects()
+	return m.result, nil
+}
+
+type merger struct {
+	opts   *Options
+	data   *ghost.DatabaseData
+	result *Result
+
+	// ids holds every id in the merged export, as Ghost ids are unique
+	// across tables.
+	ids       map[string]bool
+	postSlugs map[string]bool
+	userSlugs map[string]bool
+
+	// tags, users, roles and members map the slug, email and name of merged
+	// rows to their merged id.
+	tags    map[string]string
+	users   map[string]string
+	roles   map[string]string
+	members map[string]string
+	// joins holds the join rows merged, keyed by table and referenced ids.
+	joins map[string]bool
+
+	moves []*move
+}
+
+// move records an item whose path changed, or which came from a source with
+// a PathPrefix, for emitting redirects.
+type move struct {
+	source  *Source
+	oldPath string
+	newPath string
+}
+
+// sourceIDs maps the ids of a source to their merged ids.
+type sourceIDs map[string]string
+
+func (ids sourceIDs) remap(id *string) *string {
+	if id == nil {
+		return nil
+	}
+	if mapped, ok := ids[*id]; ok {
+		return ghost.String(mapped)
+	}
+	return id
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic("merge: generating id: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// claim reserves id for a new row, generating a fresh one if it is taken.
+func (m *merger) claim(id *string) string {
+	if id != nil && !m.ids[*id] {
+		m.ids[*id] = true
+		return *id
+	}
+	for {
+		fresh := m.opts.NewID()
+		if !m.ids[fresh] {
+			m.ids[fresh] = true
+			return fresh
+		}
+	}
+}
+
+func freeSlug(taken map[string]bool, slug string) string {
+	if !taken[slug] {
+		return slug
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", slug, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (m *merger) source(source *Source) sourceIDs {
+	data := source.DB.Data
+	ids := make(sourceIDs)
+
+	for _, role := range data.Roles {
+		name := deref(role.Name)
+		if id, ok := m.roles[name]; ok {
+			ids[deref(role.ID)] = id
+			continue
+		}
+		merged := *role
+		merged.ID = ghost.String(m.claim(role.ID))
+		ids[deref(role.ID)] = *merged.ID
+		m.roles[name] = *merged.ID
+		m.data.Roles = append(m.data.Roles, &merged)
+	}
+
+	for _, user := range data.Users {
+		email := strings.ToLower(deref(user.Email))
+		if id, ok := m.users[email]; ok && email != "" {
+			ids[deref(user.ID)] = id
+			m.moved(source, "/author/"+deref(user.Slug)+"/", "/author/"+m.userSlug(id)+"/")
+			continue
+		}
+		merged := *user
+		merged.ID = ghost.String(m.claim(user.ID))
+		ids[deref(user.ID)] = *merged.ID
+		m.users[email] = *merged.ID
+		slug := freeSlug(m.userSlugs, deref(user.Slug))
+		m.userSlugs[slug] = true
+		merged.Slug = ghost.String(slug)
+		m.moved(source, "/author/"+deref(user.Slug)+"/", "/author/"+slug+"/")
+		m.data.Users = append(m.data.Users, &merged)
+	}
+
+	firstTag := len(m.data.Tags)
+	for _, tag := range data.Tags {
+		slug := deref(tag.Slug)
+		if id, ok := m.tags[slug]; ok {
+			ids[deref(tag.ID)] = id
+			m.moved(source, "/tag/"+slug+"/", "/tag/"+slug+"/")
+			continue
+		}
+		merged := *tag
+		merged.ID = ghost.String(m.claim(tag.ID))
+		ids[deref(tag.ID)] = *merged.ID
+		m.tags[slug] = *merged.ID
+		m.moved(source, "/tag/"+slug+"/", "/tag/"+slug+"/")
+		m.data.Tags = append(m.data.Tags, &merged)
+	}
+
+	for _, member := range data.Members {
+		email := strings.ToLower(deref(member.Email))
+		if id, ok := m.members[email]; ok && email != "" {
+			ids[deref(member.ID)] = id
+			continue
+		}
+		merged := *member
+		merged.ID = ghost.String(m.claim(member.ID))
+		ids[deref(member.ID)] = *merged.ID
+		m.members[email] = *merged.ID
+		m.data.Members = append(m.data.Members, &merged)
+	}
+
+	firstPost := len(m.data.Posts)
+	skipped := make(map[string]bool)
+	for _, post := range data.Posts {
+		slug := deref(post.Slug)
+		if m.postSlugs[slug] {
+			switch m.opts.SlugStrategy {
+			case SkipDuplicate:
+				skipped[deref(post.ID)] = true
+				m.result.Skipped[source.Name] = append(m.result.Skipped[source.Name], deref(post.ID))
+				continue
+			case PrefixSourceSlug:
+				slug = source.Name + "-" + slug
+			}
+			slug = freeSlug(m.postSlugs, slug)
+		}
+		m.postSlugs[slug] = true
+
+		merged := *post
+		merged.ID = ghost.String(m.claim(post.ID))
+		ids[deref(post.ID)] = *merged.ID
+		merged.Slug = ghost.String(slug)
+		m.moved(source, "/"+deref(post.Slug)+"/", "/"+slug+"/")
+		m.data.Posts = append(m.data.Posts, &merged)
+	}
+
+	// references are remapped once all ids of the source are known, as
+	// parent tags may come after their children.
+	for _, tag := range m.data.Tags[firstTag:] {
+		tag.ParentID = ids.remap(tag.ParentID)
+		tag.CreatedBy = ids.remap(tag.CreatedBy)
+		tag.UpdatedBy = ids.remap(tag.UpdatedBy)
+	}
+	for _, post := range m.data.Posts[firstPost:] {
+		post.AuthorID = ids.remap(post.AuthorID)
+		post.CreatedBy = ids.remap(post.CreatedBy)
+		post.UpdatedBy = ids.remap(post.UpdatedBy)
+		post.PublishedBy = ids.remap(post.PublishedBy)
+	}
+
+	for _, meta := range data.PostsMeta {
+		if skipped[deref(meta.PostID)] {
+			continue
+		}
+		merged := *meta
+		merged.ID = ghost.String(m.claim(meta.ID))
+		merged.PostID = ids.remap(meta.PostID)
+		m.data.PostsMeta = append(m.data.PostsMeta, &merged)
+	}
+
+	for _, pt := range data.PostsTags {
+		if skipped[deref(pt.PostID)] {
+			continue
+		}
+		merged := *pt
+		merged.PostID = ids.remap(pt.PostID)
+		merged.TagID = ids.remap(pt.TagID)
+		if !m.join("posts_tags", merged.PostID, merged.TagID) {
+			continue
+		}
+		merged.ID = ghost.String(m.claim(pt.ID))
+		m.data.PostsTags = append(m.data.PostsTags, &merged)
+	}
+
+	for _, pa := range data.PostsAuthors {
+		if skipped[deref(pa.PostID)] {
+			continue
+		}
+		merged := *pa
+		merged.PostID = ids.remap(pa.PostID)
+		merged.AuthorID = ids.remap(pa.AuthorID)
+		if !m.join("posts_authors", merged.PostID, merged.AuthorID) {
+			continue
+		}
+		merged.ID = ghost.String(m.claim(pa.ID))
+		m.data.PostsAuthors = append(m.data.PostsAuthors, &merged)
+	}
+
+	for _, ru := range data.RolesUsers {
+		merged := *ru
+		merged.RoleID = ids.remap(ru.RoleID)
+		merged.UserID = ids.remap(ru.UserID)
+		// a deduplicated user keeps the roles it has on the earliest site.
+		if !m.join("roles_users", nil, merged.UserID) {
+			continue
+		}
+		merged.ID = ghost.String(m.claim(ru.ID))
+		m.data.RolesUsers = append(m.data.RolesUsers, &merged)
+	}
+	return ids
+}
+
+// remapMembers remaps the member_id of the rows of members_* tables in
+// extra, such as members_labels, so that they follow deduplicated members.
+func remapMembers(extra ghost.Columns, ids sourceIDs) (ghost.Columns, error) {
+	if extra == nil {
+		return nil, nil
+	}
+	remapped := make(ghost.Columns, len(extra))
+	for table, raw := range extra {
+		remapped[table] = raw
+		if !strings.HasPrefix(table, "members_") {
+			continue
+		}
+		// other columns are kept as they are.
+		var rows []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("merge: table %s: %v", table, err)
+		}
+		for _, row := range rows {
+			var id string
+			if json.Unmarshal(row["member_id"], &id) != nil || id == "" {
+				continue
+			}
+			row["member_id"], _ = json.Marshal(ids.remap(&id))
+		}
+		b, err := json.Marshal(rows)
+		if err != nil {
+			return nil, err
+		}
+		remapped[table] = b
+	}
+	return remapped, nil
+}
+
+// join records a join row, reporting false if it was already merged.
+func (m *merger) join(table string, a, b *string) bool {
+	key := table + ":" + deref(a) + ":" + deref(b)
+	if m.joins[key] {
+		return false
+	}
+	m.joins[key] = true
+	return true
+}
+
+func (m *merger) userSlug(id string) string {
+	for _, user := range m.data.Users {
+		if deref(user.ID) == id {
+			return deref(user.Slug)
+		}
+	}
+	return ""
+}
+
+func (m *merger) moved(source *Source, oldPath, newPath string) {
+	m.moves = append(m.moves, &move{source: source, oldPath: oldPath, newPath: newPath})
+}
+
+// redirects emits the redirects of all moves, see Source.PathPrefix.
+func (m *merger) redirects() {
+	// paths served by the merged site, which unprefixed redirects must not
+	// shadow.
+	served := make(map[string]bool)
+	for _, mv := range m.moves {
+		served[mv.newPath] = true
+	}
+
+	emitted := make(map[string]bool)
+	for _, mv := range m.moves {
+		from := mv.oldPath
+		if mv.source.PathPrefix != "" {
+			from = strings.TrimSuffix(mv.source.PathPrefix, "/") + mv.oldPath
+		} else if mv.oldPath == mv.newPath || served[mv.oldPath] {
+			continue
+		}
+		if emitted[from] {
+			continue
+		}
+		emitted[from] = true
+
+		m.result.Redirects = append(m.result.Redirects, &ghost.Redirect{
+			From: "^" + regexp.QuoteMeta(strings.TrimSuffix(from, "/")) + "/?$",
+			To:   mv.newPath,
+		})
+	}
+}
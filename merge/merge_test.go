@@ -0,0 +1,175 @@
+package merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/stretchr/testify/require"
+)
+
+const firstExport = `{
+	"meta": {"version": "3.15.1"},
+	"data": {
+		"posts": [{"id": "p1", "slug": "hello", "author_id": "u1"}],
+		"tags": [{"id": "t1", "slug": "news"}],
+		"posts_tags": [{"id": "pt1", "post_id": "p1", "tag_id": "t1"}],
+		"users": [{"id": "u1", "slug": "jane", "email": "jane@example.com"}],
+		"posts_authors": [{"id": "pa1", "post_id": "p1", "author_id": "u1"}],
+		"roles": [{"id": "r1", "name": "Administrator"}],
+		"roles_users": [{"id": "ru1", "role_id": "r1", "user_id": "u1"}],
+		"settings": [{"id": "s1", "key": "title", "value": "First"}]
+	}
+}`
+
+const secondExport = `{
+	"meta": {"version": "3.12.0"},
+	"data": {
+		"posts": [
+			{"id": "p1", "slug": "hello", "author_id": "u9"},
+			{"id": "p2", "slug": "travel", "author_id": "u9"}
+		],
+		"tags": [
+			{"id": "t1", "slug": "trips", "parent_id": "t2"},
+			{"id": "t2", "slug": "news"}
+		],
+		"posts_tags": [
+			{"id": "pt1", "post_id": "p1", "tag_id": "t2"},
+			{"id": "pt2", "post_id": "p2", "tag_id": "t1"}
+		],
+		"users": [{"id": "u9", "slug": "jane-doe", "email": "Jane@Example.com"}],
+		"posts_authors": [
+			{"id": "pa1", "post_id": "p1", "author_id": "u9"},
+			{"id": "pa2", "post_id": "p2", "author_id": "u9"}
+		],
+		"roles": [{"id": "r9", "name": "Administrator"}],
+		"roles_users": [{"id": "ru9", "role_id": "r9", "user_id": "u9"}],
+		"settings": [{"id": "s1", "key": "title", "value": "Second"}]
+	}
+}`
+
+func sources(t *testing.T, prefix string) []*Source {
+	var first, second ghost.Database
+	require.NoError(t, json.Unmarshal([]byte(firstExport), &first))
+	require.NoError(t, json.Unmarshal([]byte(secondExport), &second))
+	return []*Source{
+		{Name: "first", DB: &first},
+		{Name: "second", DB: &second, PathPrefix: prefix},
+	}
+}
+
+func counter() func() string {
+	n := 0
+	return func() string {
+		n++
+		return fmt.Sprintf("new%d", n)
+	}
+}
+
+func slugs(posts []*ghost.PostRow) []string {
+	var s []string
+	for _, post := range posts {
+		s = append(s, *post.Slug)
+	}
+	return s
+}
+
+func TestMerge(t *testing.T) {
+	result, err := Merge(sources(t, ""), &Options{NewID: counter()})
+	require.NoError(t, err)
+	data := result.DB.Data
+
+	require.Equal(t, "3.15.1", result.DB.Meta.Version)
+	require.Len(t, data.Settings, 1)
+	require.Equal(t, "First", *data.Settings[0].Value)
+
+	// users and roles are deduplicated, so the second site's posts belong to
+	// the first site's user.
+	require.Len(t, data.Users, 1)
+	require.Len(t, data.Roles, 1)
+	require.Len(t, data.RolesUsers, 1)
+
+	require.Equal(t, []string{"hello", "hello-2", "travel"}, slugs(data.Posts))
+	require.Equal(t, "p1", *data.Posts[0].ID)
+	require.Equal(t, "new2", *data.Posts[1].ID)
+	require.Equal(t, "u1", *data.Posts[1].AuthorID)
+
+	// the second site's news tag is merged into the first's, and its trips
+	// tag gets a fresh id as t1 is taken.
+	require.Len(t, data.Tags, 2)
+	trips := data.Tags[1]
+	require.Equal(t, "trips", *trips.Slug)
+	require.Equal(t, "new1", *trips.ID)
+	require.Equal(t, "t1", *trips.ParentID)
+
+	require.Len(t, data.PostsTags, 3)
+	require.Equal(t, "t1", *data.PostsTags[1].TagID)
+	require.Equal(t, *trips.ID, *data.PostsTags[2].TagID)
+	require.Len(t, data.PostsAuthors, 3)
+
+	require.Empty(t, result.DB.Validate(""))
+
+	// /hello/ is still served by the first site's post, so only the author
+	// gets a redirect.
+	require.Len(t, result.Redirects, 1)
+	require.Equal(t, `^/author/jane-doe/?$`, result.Redirects[0].From)
+	require.Equal(t, "/author/jane/", result.Redirects[0].To)
+}
+
+func TestMergeStrategies(t *testing.T) {
+	result, err := Merge(sources(t, ""), &Options{SlugStrategy: PrefixSourceSlug})
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello", "second-hello", "travel"}, slugs(result.DB.Data.Posts))
+
+	result, err = Merge(sources(t, ""), &Options{SlugStrategy: SkipDuplicate})
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello", "travel"}, slugs(result.DB.Data.Posts))
+	require.Equal(t, map[string][]string{"second": {"p1"}}, result.Skipped)
+	require.Len(t, result.DB.Data.PostsTags, 2)
+	require.Len(t, result.DB.Data.PostsAuthors, 2)
+}
+
+func TestMergePathPrefix(t *testing.T) {
+	result, err := Merge(sources(t, "/second/"), nil)
+	require.NoError(t, err)
+
+	redirects := make(map[string]string)
+	for _, r := range result.Redirects {
+		redirects[r.From] = r.To
+	}
+	require.Equal(t, map[string]string{
+		`^/second/author/jane-doe/?$`: "/author/jane/",
+		`^/second/tag/trips/?$`:       "/tag/trips/",
+		`^/second/tag/news/?$`:        "/tag/news/",
+		`^/second/hello/?$`:           "/hello-2/",
+		`^/second/travel/?$`:          "/travel/",
+	}, redirects)
+}
+
+func TestMergeMembers(t *testing.T) {
+	var first, second ghost.Database
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {
+		"members": [
+			{"id": "m1", "email": "ann@example.com"},
+			{"id": "m2", "email": "Ann@Example.com"}
+		],
+		"members_labels": [{"id": "ml1", "member_id": "m2", "label_id": "l1", "sort_order": 0}]
+	}}`), &first))
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {
+		"members": [{"id": "m1", "email": "bob@example.com"}]
+	}}`), &second))
+
+	opts := &Options{}
+	result, err := Merge([]*Source{{Name: "first", DB: &first}, {Name: "second", DB: &second}}, opts)
+	require.NoError(t, err)
+	require.Nil(t, opts.NewID)
+
+	data := result.DB.Data
+	require.Len(t, data.Members, 2)
+	require.Equal(t, "m1", *data.Members[0].ID)
+	require.NotEqual(t, "m1", *data.Members[1].ID)
+
+	// rows of the dropped duplicate follow the member kept.
+	require.JSONEq(t, `[{"id": "ml1", "member_id": "m1", "label_id": "l1", "sort_order": 0}]`, string(data.Extra["members_labels"]))
+}
@@ -0,0 +1,116 @@
+package mobiledoc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Builder builds a document section by section. The first error, such as
+// that of an invalid heading level, is kept and returned by Document.
+type Builder struct {
+	doc *Document
+	err error
+}
+
+// NewBuilder returns a builder of an empty document.
+func NewBuilder() *Builder {
+	return &Builder{doc: &Document{Sections: []Section{}}}
+}
+
+func (b *Builder) add(section Section) *Builder {
+	b.doc.Sections = append(b.doc.Sections, section)
+	return b
+}
+
+// Paragraph adds a paragraph of markers.
+func (b *Builder) Paragraph(markers ...*Marker) *Builder {
+	return b.add(&MarkupSection{Tag: "p", Markers: markers})
+}
+
+// Heading adds a heading of level 1 to 6.
+func (b *Builder) Heading(level int, markers ...*Marker) *Builder {
+	if level < 1 || level > 6 {
+		if b.err == nil {
+			b.err = fmt.Errorf("mobiledoc: invalid heading level %d", level)
+		}
+		return b
+	}
+	return b.add(&MarkupSection{Tag: fmt.Sprintf("h%d", level), Markers: markers})
+}
+
+// Quote adds a block quote.
+func (b *Builder) Quote(markers ...*Marker) *Builder {
+	return b.add(&MarkupSection{Tag: "blockquote", Markers: markers})
+}
+
+// List adds a bulleted list, or a numbered one if ordered, with an item per
+// list of markers.
+func (b *Builder) List(ordered bool, items ...[]*Marker) *Builder {
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	return b.add(&ListSection{Tag: tag, Items: items})
+}
+
+// Card adds a card, such as an ImageCard.
+func (b *Builder) Card(p CardPayload) *Builder {
+	card, err := NewCard(p)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	return b.add(&CardSection{Card: card})
+}
+
+// Document returns the document built.
+func (b *Builder) Document() (*Document, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.doc, nil
+}
+
+// Text returns a marker of plain text.
+func Text(text string) *Marker {
+	return &Marker{Text: text}
+}
+
+// NewMarkup returns markup of tag with attributes given as names and values.
+func NewMarkup(tag string, attributes ...string) *Markup {
+	return &Markup{Tag: tag, Attributes: attributes}
+}
+
+// Marked returns a marker of text within all of markups, the first
+// outermost.
+func Marked(text string, markups ...*Markup) *Marker {
+	return &Marker{Open: markups, Closed: len(markups), Text: text}
+}
+
+// Strong returns a marker of bold text.
+func Strong(text string) *Marker {
+	return Marked(text, NewMarkup("strong"))
+}
+
+// Em returns a marker of italic text.
+func Em(text string) *Marker {
+	return Marked(text, NewMarkup("em"))
+}
+
+// Code returns a marker of inline code.
+func Code(text string) *Marker {
+	return Marked(text, NewMarkup("code"))
+}
+
+// Link returns a marker of text linking to href.
+func Link(href, text string) *Marker {
+	return Marked(text, NewMarkup("a", "href", href))
+}
+
+// SoftReturn returns a marker of Ghost's soft-return atom, a line break
+// within a section.
+func SoftReturn() *Marker {
+	return &Marker{Atom: &Atom{Name: "soft-return", Payload: json.RawMessage("{}")}}
+}
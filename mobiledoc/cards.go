@@ -0,0 +1,115 @@
+package mobiledoc
+
+// CardPayload is the payload of a card, identifying the card by name.
+type CardPayload interface {
+	CardName() string
+}
+
+// NewCard returns the card of payload p.
+func NewCard(p CardPayload) (*Card, error) {
+	payload, err := marshalPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	return &Card{Name: p.CardName(), Payload: payload}, nil
+}
+
+// ImageCard is Ghost's image card. CardWidth is "" for the content width,
+// "wide" or "full", and Caption is HTML.
+type ImageCard struct {
+	Src       string `json:"src"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	Alt       string `json:"alt,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Caption   string `json:"caption,omitempty"`
+	CardWidth string `json:"cardWidth,omitempty"`
+	Href      string `json:"href,omitempty"`
+}
+
+// MarkdownCard is Ghost's markdown card.
+type MarkdownCard struct {
+	Markdown string `json:"markdown"`
+}
+
+// HTMLCard is Ghost's HTML card.
+type HTMLCard struct {
+	HTML string `json:"html"`
+}
+
+// BookmarkCard is Ghost's bookmark card, a preview of the page at URL.
+// Caption is HTML.
+type BookmarkCard struct {
+	URL      string           `json:"url"`
+	Metadata BookmarkMetadata `json:"metadata"`
+	Caption  string           `json:"caption,omitempty"`
+}
+
+// BookmarkMetadata is the metadata of a bookmarked page.
+type BookmarkMetadata struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Author      string `json:"author,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	Thumbnail   string `json:"thumbnail,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+}
+
+// GalleryCard is Ghost's gallery card. Caption is HTML.
+type GalleryCard struct {
+	Images  []*GalleryImage `json:"images"`
+	Caption string          `json:"caption,omitempty"`
+}
+
+// GalleryImage is an image of a gallery, shown in the row numbered Row.
+type GalleryImage struct {
+	FileName string `json:"fileName,omitempty"`
+	Row      int    `json:"row"`
+	Src      string `json:"src"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Alt      string `json:"alt,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Href     string `json:"href,omitempty"`
+}
+
+// EmbedCard is Ghost's embed card, holding the oEmbed HTML of URL. Caption
+// is HTML.
+type EmbedCard struct {
+	URL      string                 `json:"url"`
+	HTML     string                 `json:"html"`
+	Type     string                 `json:"type,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Caption  string                 `json:"caption,omitempty"`
+}
+
+// CalloutCard is the callout card of Ghost 4. CalloutText is HTML, and
+// BackgroundColor is a name such as "grey", "blue" or "accent".
+type CalloutCard struct {
+	CalloutEmoji    string `json:"calloutEmoji,omitempty"`
+	CalloutText     string `json:"calloutText"`
+	BackgroundColor string `json:"backgroundColor,omitempty"`
+}
+
+// CardName implements CardPayload.
+func (ImageCard) CardName() string { return "image" }
+
+// CardName implements CardPayload.
+func (MarkdownCard) CardName() string { return "markdown" }
+
+// CardName implements CardPayload.
+func (HTMLCard) CardName() string { return "html" }
+
+// CardName implements CardPayload.
+func (BookmarkCard) CardName() string { return "bookmark" }
+
+// CardName implements CardPayload.
+func (GalleryCard) CardName() string { return "gallery" }
+
+// CardName implements CardPayload.
+func (EmbedCard) CardName() string { return "embed" }
+
+// CardName implements CardPayload.
+func (CalloutCard) CardName() string { return "callout" }
@@ -0,0 +1,393 @@
+// Package mobiledoc reads, builds and renders mobiledoc, the JSON document
+// format of the Ghost 3 and 4 editor kept in Post.Mobiledoc. Documents are
+// typed: sections hold markers, which refer to their markups and atoms
+// directly rather than by index, and cards carry their payload as JSON,
+// which decodes into the Ghost card types of this package.
+package mobiledoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Version is the mobiledoc version Ghost writes, and that of encoded
+// documents.
+const Version = "0.3.1"
+
+// Section and marker type identifiers of the mobiledoc format.
+const (
+	markupSectionType = 1
+	imageSectionType  = 2
+	listSectionType   = 3
+	cardSectionType   = 10
+
+	textMarkerType = 0
+	atomMarkerType = 1
+)
+
+// Document is a mobiledoc document.
+type Document struct {
+	Sections []Section
+}
+
+// Section is one of *MarkupSection, *ImageSection, *ListSection or
+// *CardSection.
+type Section interface {
+	isSection()
+}
+
+// MarkupSection is a block of text, such as a paragraph (tag "p"), a heading
+// ("h1" to "h6") or a quote ("blockquote" or "aside").
+type MarkupSection struct {
+	Tag     string
+	Markers []*Marker
+}
+
+// ImageSection is a plain image. Ghost's editor uses image cards instead.
+type ImageSection struct {
+	Src string
+}
+
+// ListSection is a list, with tag "ul" or "ol", of items of text.
+type ListSection struct {
+	Tag   string
+	Items [][]*Marker
+}
+
+// CardSection places a card.
+type CardSection struct {
+	Card *Card
+}
+
+func (*MarkupSection) isSection() {}
+func (*ImageSection) isSection()  {}
+func (*ListSection) isSection()   {}
+func (*CardSection) isSection()   {}
+
+// Markup is inline markup, such as "strong" or "a", with attributes as a
+// flat list of names and values, e.g. ["href", "https://ghost.org"].
+type Markup struct {
+	Tag        string
+	Attributes []string
+}
+
+// Marker is a run of text, or an atom when Atom is set. Before the marker the
+// Open markups are opened, and after it the innermost Closed markups, of
+// those open, are closed.
+type Marker struct {
+	Open   []*Markup
+	Closed int
+	Text   string
+	Atom   *Atom
+}
+
+// Atom is an inline card, such as Ghost's "soft-return" line break.
+type Atom struct {
+	Name    string
+	Text    string
+	Payload json.RawMessage
+}
+
+// Card is a block card, such as Ghost's "image" or "html" cards. Payload is
+// decoded with Decode.
+type Card struct {
+	Name    string
+	Payload json.RawMessage
+}
+
+// Decode decodes the payload of c into v, typically one of the Ghost card
+// types of this package.
+func (c *Card) Decode(v interface{}) error {
+	if len(c.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(c.Payload, v)
+}
+
+// marshalPayload encodes v without escaping HTML, as Ghost does.
+func marshalPayload(v interface{}) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
+
+// rawDocument is the encoded form of a document, in Ghost's key order.
+type rawDocument struct {
+	Version  string            `json:"version"`
+	Atoms    []json.RawMessage `json:"atoms"`
+	Cards    []json.RawMessage `json:"cards"`
+	Markups  []json.RawMessage `json:"markups"`
+	Sections []json.RawMessage `json:"sections"`
+}
+
+// Parse decodes a mobiledoc document, such as the Mobiledoc of a post.
+func Parse(s string) (*Document, error) {
+	doc := &Document{}
+	if err := json.Unmarshal([]byte(s), doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Marshal encodes d as Ghost does, without escaping HTML.
+func (d *Document) Marshal() (string, error) {
+	b, err := marshalPayload(d)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// decodeTuple decodes the JSON array raw into fields, of which the first
+// required must be present.
+func decodeTuple(raw json.RawMessage, required int, fields ...interface{}) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+	if len(items) < required {
+		return fmt.Errorf("mobiledoc: %s has fewer than %d elements", raw, required)
+	}
+	for i, item := range items {
+		if i >= len(fields) {
+			break
+		}
+		if err := json.Unmarshal(item, fields[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler for mobiledoc 0.3.
+func (d *Document) UnmarshalJSON(b []byte) error {
+	var raw rawDocument
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(raw.Version, "0.3") {
+		return fmt.Errorf("mobiledoc: unsupported version %q", raw.Version)
+	}
+
+	markups := make([]*Markup, len(raw.Markups))
+	for i, r := range raw.Markups {
+		markups[i] = &Markup{}
+		if err := decodeTuple(r, 1, &markups[i].Tag, &markups[i].Attributes); err != nil {
+			return err
+		}
+	}
+	atoms := make([]*Atom, len(raw.Atoms))
+	for i, r := range raw.Atoms {
+		atoms[i] = &Atom{}
+		if err := decodeTuple(r, 2, &atoms[i].Name, &atoms[i].Text, &atoms[i].Payload); err != nil {
+			return err
+		}
+	}
+	cards := make([]*Card, len(raw.Cards))
+	for i, r := range raw.Cards {
+		cards[i] = &Card{}
+		if err := decodeTuple(r, 1, &cards[i].Name, &cards[i].Payload); err != nil {
+			return err
+		}
+	}
+
+	decodeMarkers := func(raw []json.RawMessage) ([]*Marker, error) {
+		markers := make([]*Marker, len(raw))
+		for i, r := range raw {
+			var (
+				kind  int
+				open  []int
+				value json.RawMessage
+			)
+			m := &Marker{}
+			if err := decodeTuple(r, 4, &kind, &open, &m.Closed, &value); err != nil {
+				return nil, err
+			}
+			for _, index := range open {
+				if index < 0 || index >= len(markups) {
+					return nil, fmt.Errorf("mobiledoc: markup %d out of range", index)
+				}
+				m.Open = append(m.Open, markups[index])
+			}
+			switch kind {
+			case textMarkerType:
+				if err := json.Unmarshal(value, &m.Text); err != nil {
+					return nil, err
+				}
+			case atomMarkerType:
+				var index int
+				if err := json.Unmarshal(value, &index); err != nil {
+					return nil, err
+				}
+				if index < 0 || index >= len(atoms) {
+					return nil, fmt.Errorf("mobiledoc: atom %d out of range", index)
+				}
+				m.Atom = atoms[index]
+			default:
+				return nil, fmt.Errorf("mobiledoc: unknown marker type %d", kind)
+			}
+			markers[i] = m
+		}
+		return markers, nil
+	}
+
+	d.Sections = make([]Section, 0, len(raw.Sections))
+	for _, r := range raw.Sections {
+		var kind int
+		if err := decodeTuple(r, 1, &kind); err != nil {
+			return err
+		}
+		switch kind {
+		case markupSectionType:
+			var tag string
+			var rawMarkers []json.RawMessage
+			if err := decodeTuple(r, 3, &kind, &tag, &rawMarkers); err != nil {
+				return err
+			}
+			markers, err := decodeMarkers(rawMarkers)
+			if err != nil {
+				return err
+			}
+			d.Sections = append(d.Sections, &MarkupSection{Tag: tag, Markers: markers})
+		case imageSectionType:
+			section := &ImageSection{}
+			if err := decodeTuple(r, 2, &kind, &section.Src); err != nil {
+				return err
+			}
+			d.Sections = append(d.Sections, section)
+		case listSectionType:
+			var tag string
+			var rawItems [][]json.RawMessage
+			if err := decodeTuple(r, 3, &kind, &tag, &rawItems); err != nil {
+				return err
+			}
+			section := &ListSection{Tag: tag}
+			for _, rawMarkers := range rawItems {
+				markers, err := decodeMarkers(rawMarkers)
+				if err != nil {
+					return err
+				}
+				section.Items = append(section.Items, markers)
+			}
+			d.Sections = append(d.Sections, section)
+		case cardSectionType:
+			var index int
+			if err := decodeTuple(r, 2, &kind, &index); err != nil {
+				return err
+			}
+			if index < 0 || index >= len(cards) {
+				return fmt.Errorf("mobiledoc: card %d out of range", index)
+			}
+			d.Sections = append(d.Sections, &CardSection{Card: cards[index]})
+		default:
+			return fmt.Errorf("mobiledoc: unknown section type %d", kind)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding mobiledoc 0.3.1. Equal
+// markups are shared, while every atom and card is listed separately.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	raw := rawDocument{
+		Version:  Version,
+		Atoms:    []json.RawMessage{},
+		Cards:    []json.RawMessage{},
+		Markups:  []json.RawMessage{},
+		Sections: []json.RawMessage{},
+	}
+
+	add := func(list *[]json.RawMessage, v interface{}) (int, error) {
+		b, err := marshalPayload(v)
+		if err != nil {
+			return 0, err
+		}
+		*list = append(*list, b)
+		return len(*list) - 1, nil
+	}
+	payload := func(p json.RawMessage) json.RawMessage {
+		if len(p) == 0 {
+			return json.RawMessage("{}")
+		}
+		return p
+	}
+
+	markupIndex := make(map[string]int)
+	encodeMarkers := func(markers []*Marker) ([]interface{}, error) {
+		encoded := make([]interface{}, 0, len(markers))
+		for _, m := range markers {
+			open := make([]int, 0, len(m.Open))
+			for _, markup := range m.Open {
+				key := markup.Tag + "\x00" + strings.Join(markup.Attributes, "\x00")
+				index, ok := markupIndex[key]
+				if !ok {
+					var tuple []interface{}
+					if len(markup.Attributes) > 0 {
+						tuple = []interface{}{markup.Tag, markup.Attributes}
+					} else {
+						tuple = []interface{}{markup.Tag}
+					}
+					var err error
+					if index, err = add(&raw.Markups, tuple); err != nil {
+						return nil, err
+					}
+					markupIndex[key] = index
+				}
+				open = append(open, index)
+			}
+
+			if m.Atom == nil {
+				encoded = append(encoded, []interface{}{textMarkerType, open, m.Closed, m.Text})
+				continue
+			}
+			index, err := add(&raw.Atoms, []interface{}{m.Atom.Name, m.Atom.Text, payload(m.Atom.Payload)})
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, []interface{}{atomMarkerType, open, m.Closed, index})
+		}
+		return encoded, nil
+	}
+
+	for _, section := range d.Sections {
+		var tuple []interface{}
+		switch s := section.(type) {
+		case *MarkupSection:
+			markers, err := encodeMarkers(s.Markers)
+			if err != nil {
+				return nil, err
+			}
+			tuple = []interface{}{markupSectionType, s.Tag, markers}
+		case *ImageSection:
+			tuple = []interface{}{imageSectionType, s.Src}
+		case *ListSection:
+			items := make([]interface{}, 0, len(s.Items))
+			for _, item := range s.Items {
+				markers, err := encodeMarkers(item)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, markers)
+			}
+			tuple = []interface{}{listSectionType, s.Tag, items}
+		case *CardSection:
+			index, err := add(&raw.Cards, []interface{}{s.Card.Name, payload(s.Card.Payload)})
+			if err != nil {
+				return nil, err
+			}
+			tuple = []interface{}{cardSectionType, index}
+		default:
+			return nil, fmt.Errorf("mobiledoc: unknown section %T", section)
+		}
+		if _, err := add(&raw.Sections, tuple); err != nil {
+			return nil, err
+		}
+	}
+	return marshalPayload(raw)
+}
@@ -0,0 +1,106 @@
+package mobiledoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testMobiledoc = `{"version":"0.3.1","atoms":[["soft-return","",{}]],"cards":[["image",{"src":"/content/images/a.jpg","alt":"A","caption":"An <em>image</em>","cardWidth":"wide"}],["html",{"html":"<div>raw</div>"}]],"markups":[["strong"],["a",["href","https://ghost.org"]]],"sections":[[1,"h2",[[0,[],0,"Hello & welcome"]]],[1,"p",[[0,[0],0,"bold "],[0,[1],2,"link"],[1,[],0,0],[0,[],0,"x < y"]]],[10,0],[3,"ul",[[[0,[],0,"one"]],[[0,[0],1,"two"]]]],[2,"/b.png"],[10,1],[1,"h2",[[0,[],0,"Hello & welcome"]]]]}`
+
+func TestParseRoundTrip(t *testing.T) {
+	doc, err := Parse(testMobiledoc)
+	require.NoError(t, err)
+	require.Len(t, doc.Sections, 7)
+
+	p := doc.Sections[1].(*MarkupSection)
+	require.Equal(t, "p", p.Tag)
+	require.Equal(t, "strong", p.Markers[0].Open[0].Tag)
+	require.Equal(t, []string{"href", "https://ghost.org"}, p.Markers[1].Open[0].Attributes)
+	require.Equal(t, "soft-return", p.Markers[2].Atom.Name)
+
+	var image ImageCard
+	require.NoError(t, doc.Sections[2].(*CardSection).Card.Decode(&image))
+	require.Equal(t, "wide", image.CardWidth)
+
+	s, err := doc.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, testMobiledoc, s)
+
+	_, err = Parse(`{"version":"0.3.1","sections":[[10,0]]}`)
+	require.Error(t, err)
+	_, err = Parse(`{"version":"0.2.0","sections":[]}`)
+	require.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	doc, err := Parse(testMobiledoc)
+	require.NoError(t, err)
+
+	html, err := doc.HTML()
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		`<h2 id="hello-welcome">Hello &amp; welcome</h2>`,
+		`<p><strong>bold <a href="https://ghost.org">link</a></strong><br>x &lt; y</p>`,
+		`<figure class="kg-card kg-image-card kg-width-wide kg-card-hascaption"><img src="/content/images/a.jpg" class="kg-image" alt="A"><figcaption>An <em>image</em></figcaption></figure>`,
+		`<ul><li>one</li><li><strong>two</strong></li></ul>`,
+		`<img src="/b.png">`,
+		`<!--kg-card-begin: html--><div>raw</div><!--kg-card-end: html-->`,
+		`<h2 id="hello-welcome-1">Hello &amp; welcome</h2>`,
+	}, ""), html)
+}
+
+func TestBuilder(t *testing.T) {
+	doc, err := NewBuilder().
+		Heading(1, Text("Title")).
+		Paragraph(Text("Read "), Link("javascript:alert(1)", "this"), SoftReturn(), Em("now")).
+		List(true, []*Marker{Code("go")}).
+		Card(MarkdownCard{Markdown: "# Hi"}).
+		Card(BookmarkCard{URL: "https://ghost.org", Metadata: BookmarkMetadata{
+			URL: "https://ghost.org", Title: "Ghost", Description: "Publishing", Publisher: "Ghost", Icon: "/i.png",
+		}}).
+		Card(GalleryCard{Images: []*GalleryImage{
+			{Src: "/1.jpg", Width: 10, Height: 10},
+			{Src: "/2.jpg", Width: 10, Height: 10, Row: 1},
+		}}).
+		Card(EmbedCard{URL: "https://youtu.be/x", HTML: "<iframe></iframe>", Caption: "Video"}).
+		Card(CalloutCard{CalloutEmoji: "💡", CalloutText: "Note"}).
+		Document()
+	require.NoError(t, err)
+
+	_, err = doc.HTML()
+	require.Error(t, err, "markdown cards need a markdown renderer")
+
+	r := &Renderer{Markdown: func(md string) (string, error) { return "<h1>Hi</h1>", nil }}
+	html, err := r.Render(doc)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		`<h1 id="title">Title</h1>`,
+		`<p>Read <a href="unsafe:javascript:alert(1)">this</a><br><em>now</em></p>`,
+		`<ol><li><code>go</code></li></ol>`,
+		`<!--kg-card-begin: markdown--><h1>Hi</h1><!--kg-card-end: markdown-->`,
+		`<figure class="kg-card kg-bookmark-card"><a class="kg-bookmark-container" href="https://ghost.org"><div class="kg-bookmark-content"><div class="kg-bookmark-title">Ghost</div><div class="kg-bookmark-description">Publishing</div><div class="kg-bookmark-metadata"><img class="kg-bookmark-icon" src="/i.png"><span class="kg-bookmark-author">Ghost</span></div></div></a></figure>`,
+		`<figure class="kg-card kg-gallery-card kg-width-wide"><div class="kg-gallery-container"><div class="kg-gallery-row"><div class="kg-gallery-image"><img src="/1.jpg" width="10" height="10" alt=""></div></div><div class="kg-gallery-row"><div class="kg-gallery-image"><img src="/2.jpg" width="10" height="10" alt=""></div></div></div></figure>`,
+		`<figure class="kg-card kg-embed-card kg-card-hascaption"><iframe></iframe><figcaption>Video</figcaption></figure>`,
+		`<div class="kg-card kg-callout-card kg-callout-card-grey"><div class="kg-callout-emoji">💡</div><div class="kg-callout-text">Note</div></div>`,
+	}, ""), html)
+
+	// the built document survives encoding.
+	s, err := doc.Marshal()
+	require.NoError(t, err)
+	parsed, err := Parse(s)
+	require.NoError(t, err)
+	again, err := parsed.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, s, again)
+
+	_, err = NewBuilder().Heading(7, Text("x")).Document()
+	require.Error(t, err)
+
+	r.Cards = map[string]CardRenderer{"callout": func(*Card) (string, error) { return "<hr>", nil }}
+	doc, _ = NewBuilder().Card(CalloutCard{CalloutText: "x"}).Document()
+	html, err = r.Render(doc)
+	require.NoError(t, err)
+	require.Equal(t, "<hr>", html)
+}
@@ -0,0 +1,323 @@
+package mobiledoc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pubbit-co/go-ghost/internal/render"
+)
+
+// CardRenderer renders a card to HTML.
+type CardRenderer func(card *Card) (string, error)
+
+// Renderer renders documents to HTML as Ghost does, though without the
+// responsive image attributes of later Ghost versions. Ghost's image,
+// markdown, html, bookmark, gallery, embed and callout cards are rendered;
+// other cards fail to render unless given in Cards.
+type Renderer struct {
+	// Markdown renders the markdown of markdown cards to HTML. Ghost uses
+	// markdown-it; without Markdown, markdown cards fail to render.
+	Markdown func(markdown string) (string, error)
+	// Cards render cards by name, in addition to or instead of Ghost's.
+	Cards map[string]CardRenderer
+}
+
+// HTML renders d with the default renderer.
+func (d *Document) HTML() (string, error) {
+	return (&Renderer{}).Render(d)
+}
+
+var (
+	sectionTags = map[string]bool{
+		"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"blockquote": true, "aside": true,
+	}
+	markupTags = map[string]bool{
+		"a": true, "b": true, "code": true, "em": true, "i": true, "s": true,
+		"strong": true, "sub": true, "sup": true, "u": true,
+	}
+)
+
+// attrs renders attributes given as names and values.
+func attrs(pairs ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fmt.Fprintf(&sb, ` %s="%s"`, pairs[i], render.EscapeAttr(pairs[i+1]))
+	}
+	return sb.String()
+}
+
+// Render renders doc to HTML.
+func (r *Renderer) Render(doc *Document) (string, error) {
+	var sb strings.Builder
+	ids := make(render.HeadingIDs)
+	for _, section := range doc.Sections {
+		switch s := section.(type) {
+		case *MarkupSection:
+			if !sectionTags[s.Tag] {
+				return "", fmt.Errorf("mobiledoc: invalid section tag %q", s.Tag)
+			}
+			content, text, err := renderMarkers(s.Markers)
+			if err != nil {
+				return "", err
+			}
+			// Ghost gives headings an id, unique within the document.
+			var id string
+			if len(s.Tag) == 2 && s.Tag[0] == 'h' {
+				id = attrs("id", ids.ID(text))
+			}
+			fmt.Fprintf(&sb, "<%s%s>%s</%s>", s.Tag, id, content, s.Tag)
+		case *ImageSection:
+			fmt.Fprintf(&sb, "<img%s>", attrs("src", s.Src))
+		case *ListSection:
+			if s.Tag != "ul" && s.Tag != "ol" {
+				return "", fmt.Errorf("mobiledoc: invalid list tag %q", s.Tag)
+			}
+			sb.WriteString("<" + s.Tag + ">")
+			for _, item := range s.Items {
+				content, _, err := renderMarkers(item)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString("<li>" + content + "</li>")
+			}
+			sb.WriteString("</" + s.Tag + ">")
+		case *CardSection:
+			html, err := r.RenderCard(s.Card)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(html)
+		default:
+			return "", fmt.Errorf("mobiledoc: unknown section %T", section)
+		}
+	}
+	return sb.String(), nil
+}
+
+// renderMarkers renders markers to HTML, also returning their plain text.
+func renderMarkers(markers []*Marker) (string, string, error) {
+	var sb, text strings.Builder
+	var open []string
+	for _, m := range markers {
+		for _, markup := range m.Open {
+			tag := strings.ToLower(markup.Tag)
+			if !markupTags[tag] {
+				return "", "", fmt.Errorf("mobiledoc: invalid markup tag %q", markup.Tag)
+			}
+			pairs := append([]string{}, markup.Attributes...)
+			for i := 0; i+1 < len(pairs); i += 2 {
+				if pairs[i] == "href" {
+					pairs[i+1] = render.SanitizeHref(pairs[i+1])
+				}
+			}
+			sb.WriteString("<" + tag + attrs(pairs...) + ">")
+			open = append(open, tag)
+		}
+
+		switch {
+		case m.Atom == nil:
+			sb.WriteString(render.EscapeText(m.Text))
+			text.WriteString(m.Text)
+		case m.Atom.Name == "soft-return":
+			sb.WriteString("<br>")
+		default:
+			sb.WriteString(render.EscapeText(m.Atom.Text))
+			text.WriteString(m.Atom.Text)
+		}
+
+		if m.Closed > len(open) {
+			return "", "", fmt.Errorf("mobiledoc: marker closes %d markups of %d open", m.Closed, len(open))
+		}
+		for i := 0; i < m.Closed; i++ {
+			sb.WriteString("</" + open[len(open)-1] + ">")
+			open = open[:len(open)-1]
+		}
+	}
+	// unclosed markups are closed at the end of the section.
+	for i := len(open) - 1; i >= 0; i-- {
+		sb.WriteString("</" + open[i] + ">")
+	}
+	return sb.String(), text.String(), nil
+}
+
+// RenderCard renders card to HTML.
+func (r *Renderer) RenderCard(card *Card) (string, error) {
+	if render, ok := r.Cards[card.Name]; ok {
+		return render(card)
+	}
+
+	switch card.Name {
+	case "image":
+		var p ImageCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		return renderImageCard(&p), nil
+	case "markdown", "card-markdown":
+		var p MarkdownCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		if p.Markdown == "" {
+			return "", nil
+		}
+		if r.Markdown == nil {
+			return "", fmt.Errorf("mobiledoc: no markdown renderer for card %q", card.Name)
+		}
+		html, err := r.Markdown(p.Markdown)
+		if err != nil {
+			return "", err
+		}
+		return "<!--kg-card-begin: markdown-->" + html + "<!--kg-card-end: markdown-->", nil
+	case "html":
+		var p HTMLCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		if p.HTML == "" {
+			return "", nil
+		}
+		return "<!--kg-card-begin: html-->" + p.HTML + "<!--kg-card-end: html-->", nil
+	case "bookmark":
+		var p BookmarkCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		return renderBookmarkCard(&p), nil
+	case "gallery":
+		var p GalleryCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		return renderGalleryCard(&p), nil
+	case "embed":
+		var p EmbedCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		if p.HTML == "" {
+			return "", nil
+		}
+		return figure("kg-embed-card", p.Caption, p.HTML), nil
+	case "callout":
+		var p CalloutCard
+		if err := card.Decode(&p); err != nil {
+			return "", err
+		}
+		color := p.BackgroundColor
+		if color == "" {
+			color = "grey"
+		}
+		var sb strings.Builder
+		sb.WriteString("<div" + attrs("class", "kg-card kg-callout-card kg-callout-card-"+color) + ">")
+		if p.CalloutEmoji != "" {
+			sb.WriteString(`<div class="kg-callout-emoji">` + render.EscapeText(p.CalloutEmoji) + "</div>")
+		}
+		sb.WriteString(`<div class="kg-callout-text">` + p.CalloutText + "</div></div>")
+		return sb.String(), nil
+	}
+	return "", fmt.Errorf("mobiledoc: no renderer for card %q", card.Name)
+}
+
+// figure wraps content in the figure of a card of class, with caption.
+func figure(class, caption, content string, extraClasses ...string) string {
+	classes := append([]string{"kg-card", class}, extraClasses...)
+	if caption != "" {
+		classes = append(classes, "kg-card-hascaption")
+		content += "<figcaption>" + caption + "</figcaption>"
+	}
+	return "<figure" + attrs("class", strings.Join(classes, " ")) + ">" + content + "</figure>"
+}
+
+func renderImageCard(p *ImageCard) string {
+	if p.Src == "" {
+		return ""
+	}
+	pairs := []string{"src", p.Src, "class", "kg-image", "alt", p.Alt}
+	if p.Title != "" {
+		pairs = append(pairs, "title", p.Title)
+	}
+	if p.Width > 0 && p.Height > 0 {
+		pairs = append(pairs, "width", strconv.Itoa(p.Width), "height", strconv.Itoa(p.Height))
+	}
+	img := "<img" + attrs(pairs...) + ">"
+	if p.Href != "" {
+		img = "<a" + attrs("href", p.Href) + ">" + img + "</a>"
+	}
+	var extra []string
+	if p.CardWidth != "" {
+		extra = append(extra, "kg-width-"+p.CardWidth)
+	}
+	return figure("kg-image-card", p.Caption, img, extra...)
+}
+
+func renderBookmarkCard(p *BookmarkCard) string {
+	m := p.Metadata
+	if m.URL == "" || m.Title == "" || m.Description == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<a" + attrs("class", "kg-bookmark-container", "href", m.URL) + ">")
+	sb.WriteString(`<div class="kg-bookmark-content">`)
+	sb.WriteString(`<div class="kg-bookmark-title">` + render.EscapeText(m.Title) + "</div>")
+	sb.WriteString(`<div class="kg-bookmark-description">` + render.EscapeText(m.Description) + "</div>")
+	sb.WriteString(`<div class="kg-bookmark-metadata">`)
+	if m.Icon != "" {
+		sb.WriteString("<img" + attrs("class", "kg-bookmark-icon", "src", m.Icon) + ">")
+	}
+	// Ghost shows the author after the publisher, in these classes.
+	if m.Publisher != "" {
+		sb.WriteString(`<span class="kg-bookmark-author">` + render.EscapeText(m.Publisher) + "</span>")
+	}
+	if m.Author != "" {
+		sb.WriteString(`<span class="kg-bookmark-publisher">` + render.EscapeText(m.Author) + "</span>")
+	}
+	sb.WriteString("</div></div>")
+	if m.Thumbnail != "" {
+		sb.WriteString(`<div class="kg-bookmark-thumbnail"><img` + attrs("src", m.Thumbnail) + "></div>")
+	}
+	sb.WriteString("</a>")
+	return figure("kg-bookmark-card", p.Caption, sb.String())
+}
+
+func renderGalleryCard(p *GalleryCard) string {
+	rows := make(map[int][]*GalleryImage)
+	for _, image := range p.Images {
+		// like Ghost, images without a size are left out.
+		if image.Src == "" || image.Width == 0 || image.Height == 0 {
+			continue
+		}
+		rows[image.Row] = append(rows[image.Row], image)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	var order []int
+	for row := range rows {
+		order = append(order, row)
+	}
+	sort.Ints(order)
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="kg-gallery-container">`)
+	for _, row := range order {
+		sb.WriteString(`<div class="kg-gallery-row">`)
+		for _, image := range rows[row] {
+			pairs := []string{"src", image.Src, "width", strconv.Itoa(image.Width), "height", strconv.Itoa(image.Height), "alt", image.Alt}
+			if image.Title != "" {
+				pairs = append(pairs, "title", image.Title)
+			}
+			img := "<img" + attrs(pairs...) + ">"
+			if image.Href != "" {
+				img = "<a" + attrs("href", image.Href) + ">" + img + "</a>"
+			}
+			sb.WriteString(`<div class="kg-gallery-image">` + img + "</div>")
+		}
+		sb.WriteString("</div>")
+	}
+	sb.WriteString("</div>")
+	return figure("kg-gallery-card", p.Caption, sb.String(), "kg-width-wide")
+}
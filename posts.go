@@ -1,6 +1,7 @@
 package ghost
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -42,13 +43,15 @@ type Author struct {
 	URL             *string    `json:"url"`
 }
 
-// Post represents a Ghost post.
+// Post represents a Ghost post. Lexical, the content of Ghost 5 and later,
+// is only sent when set, as earlier versions reject it.
 type Post struct {
 	Slug               *string    `json:"slug"`
 	ID                 *string    `json:"id"`
 	UUID               *string    `json:"uuid"`
 	Title              *string    `json:"title"`
 	Mobiledoc          *string    `json:"mobiledoc"`
+	Lexical            *string    `json:"lexical,omitempty"`
 	HTML               *string    `json:"html"`
 	CommentID          *string    `json:"comment_id"`
 	FeatureImage       *string    `json:"feature_image"`
@@ -95,39 +98,133 @@ func (pr PostsResponse) String() string {
 }
 
 // Get fetches a post by id.
-func (s *AdminPostsService) Get(id string) (*Post, error) {
+func (s *AdminPostsService) Get(id string) (*Post, *Response, error) {
 	u := fmt.Sprintf("posts/%v", id)
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	postsResponse := new(PostsResponse)
-	_, err = s.client.Do(req, postsResponse)
+	resp, err := s.client.Do(req, postsResponse)
 	if err != nil {
-		return nil, err
+		return nil, newResponse(resp), err
 	}
 
-	return postsResponse.Posts[0], nil
+	return postsResponse.Posts[0], newResponse(resp), nil
 }
 
 // List fetches all posts via the ListParams.
-func (s *AdminPostsService) List(listParams *ListParams) (*PostsResponse, error) {
+func (s *AdminPostsService) List(listParams *ListParams) (*PostsResponse, *Response, error) {
 	u, err := addOptions("posts", listParams)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	req, err := s.client.NewRequest("GET", u, nil)
 	if err != nil {
+		return nil, nil, err
+	}
+
+	postsResponse := new(PostsResponse)
+	resp, err := s.client.Do(req, postsResponse)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+
+	response := newResponse(resp)
+	response.Meta = postsResponse.Meta
+	return postsResponse, response, nil
+}
+
+// dropNulls removes null fields from decoded JSON objects, recursively.
+func dropNulls(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if value == nil {
+				delete(v, key)
+			} else {
+				dropNulls(value)
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			dropNulls(value)
+		}
+	}
+}
+
+// postRequest is the body of post writes. Ghost treats null as a value, so
+// nil fields, also of tags and authors, are left out, leaving them unchanged
+// on update, except for the cleared fields, which are sent as null.
+func postRequest(post *Post, cleared []string) (interface{}, error) {
+	b, err := json.Marshal(post)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
 		return nil, err
 	}
+	dropNulls(fields)
+	for _, field := range cleared {
+		if _, ok := fields[field]; ok {
+			return nil, fmt.Errorf("cleared field %q is set", field)
+		}
+		fields[field] = nil
+	}
+	return map[string][]interface{}{"posts": {fields}}, nil
+}
+
+func (s *AdminPostsService) write(method, u string, post *Post, cleared []string) (*Post, *Response, error) {
+	body, err := postRequest(post, cleared)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(method, u, body)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	postsResponse := new(PostsResponse)
-	_, err = s.client.Do(req, postsResponse)
+	resp, err := s.client.Do(req, postsResponse)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+	if len(postsResponse.Posts) == 0 {
+		return nil, newResponse(resp), fmt.Errorf("no post in response")
+	}
+	return postsResponse.Posts[0], newResponse(resp), nil
+}
+
+// Create creates a post, returning it as created. Tags and authors are
+// matched by id, or else by slug, email or name, and new tags are created.
+func (s *AdminPostsService) Create(post *Post) (*Post, *Response, error) {
+	return s.write("POST", "posts/", post, nil)
+}
+
+// Update updates the post with post.ID. post.UpdatedAt must be that of the
+// post as last fetched: if the post has been updated since, Ghost refuses
+// the update with a 409 Conflict *ErrorResponse. Nil fields are left
+// unchanged, except for those named in cleared by their JSON name, such as
+// "feature_image", which are cleared. Tags and authors are cleared by
+// setting them to empty slices.
+func (s *AdminPostsService) Update(post *Post, cleared ...string) (*Post, *Response, error) {
+	if post.ID == nil || post.UpdatedAt == nil {
+		return nil, nil, fmt.Errorf("updating a post needs its id and updated_at")
+	}
+	return s.write("PUT", fmt.Sprintf("posts/%v/", *post.ID), post, cleared)
+}
+
+// Delete deletes a post by id.
+func (s *AdminPostsService) Delete(id string) (*Response, error) {
+	req, err := s.client.NewRequest("DELETE", fmt.Sprintf("posts/%v/", id), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return postsResponse, nil
+	resp, err := s.client.Do(req, nil)
+	return newResponse(resp), err
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPost_marshall(t *testing.T) {
@@ -228,7 +229,7 @@ func TestPostsService_Get(t *testing.T) {
 		fmt.Fprint(w, `{ "posts": [{"id": "1"}] }`)
 	})
 
-	post, err := client.Posts.Get("1")
+	post, _, err := client.Posts.Get("1")
 	if err != nil {
 		t.Errorf("Posts.Get returned error: %v", err)
 	}
@@ -246,8 +247,11 @@ func TestPostsService_List(t *testing.T) {
 	mux.HandleFunc(BaseAdminPath+"posts/", func(w http.ResponseWriter, r *http.Request) {
 		testMethod(t, r, "GET")
 		testFormValues(t, r, map[string]string{
-			"page": "2",
+			"page":    "2",
+			"include": "tags,authors",
+			"formats": "html",
 		})
+		w.Header().Set("Content-Version", "v3.15")
 		fmt.Fprint(w, `{ 
 			"posts": [
 				{"id": "1"}
@@ -259,7 +263,10 @@ func TestPostsService_List(t *testing.T) {
 			}}`)
 	})
 
-	post, err := client.Posts.List(&ListParams{Page: 2})
+	post, resp, err := client.Posts.List(&ListParams{
+		QueryParams: QueryParams{Include: "tags,authors", Formats: "html"},
+		Page:        2,
+	})
 	if err != nil {
 		t.Errorf("Posts.List returned error: %v", err)
 	}
@@ -278,4 +285,75 @@ func TestPostsService_List(t *testing.T) {
 	if !reflect.DeepEqual(post, want) {
 		t.Errorf("Posts.List returned %+v, want %+v", post, want)
 	}
+	if !reflect.DeepEqual(resp.Meta, want.Meta) {
+		t.Errorf("Posts.List response meta %+v, want %+v", resp.Meta, want.Meta)
+	}
+	if resp.ContentVersion != "v3.15" {
+		t.Errorf("Posts.List response content version %q, want %q", resp.ContentVersion, "v3.15")
+	}
+}
+
+func TestPostsService_Create(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"posts/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testBody(t, r, `{"posts":[{"tags":[{"name":"News"}],"title":"Hello"}]}`+"\n")
+		fmt.Fprint(w, `{"posts": [{"id": "1", "title": "Hello"}]}`)
+	})
+
+	post, _, err := client.Posts.Create(&Post{Title: String("Hello"), Tags: []*Tag{{Name: String("News")}}})
+	if err != nil {
+		t.Errorf("Posts.Create returned error: %v", err)
+	}
+
+	want := &Post{ID: String("1"), Title: String("Hello")}
+	if !reflect.DeepEqual(post, want) {
+		t.Errorf("Posts.Create returned %+v, want %+v", post, want)
+	}
+}
+
+func TestPostsService_Update(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"posts/1/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testBody(t, r, `{"posts":[{"feature_image":null,"id":"1","status":"draft","tags":[],"updated_at":"2020-01-02T03:04:05Z"}]}`+"\n")
+		fmt.Fprint(w, `{"posts": [{"id": "1", "status": "draft"}]}`)
+	})
+
+	updatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	update := &Post{ID: String("1"), Status: String("draft"), Tags: []*Tag{}, UpdatedAt: &updatedAt}
+	post, _, err := client.Posts.Update(update, "feature_image")
+	if err != nil {
+		t.Errorf("Posts.Update returned error: %v", err)
+	}
+
+	want := &Post{ID: String("1"), Status: String("draft")}
+	if !reflect.DeepEqual(post, want) {
+		t.Errorf("Posts.Update returned %+v, want %+v", post, want)
+	}
+
+	if _, _, err := client.Posts.Update(&Post{ID: String("1")}); err == nil {
+		t.Errorf("Posts.Update without updated_at returned no error")
+	}
+	if _, _, err := client.Posts.Update(update, "status"); err == nil {
+		t.Errorf("Posts.Update clearing a set field returned no error")
+	}
+}
+
+func TestPostsService_Delete(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"posts/1/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if _, err := client.Posts.Delete("1"); err != nil {
+		t.Errorf("Posts.Delete returned error: %v", err)
+	}
 }
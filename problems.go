@@ -0,0 +1,173 @@
+package ghost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProblemKind classifies a DatabaseImportProblem.
+type ProblemKind string
+
+// Kinds of import problems.
+const (
+	// ProblemDuplicate is an entry ignored because an equal one exists.
+	ProblemDuplicate ProblemKind = "duplicate"
+	// ProblemMissingReference is an entry referencing a record that does
+	// not exist, such as a post tag of an unknown tag.
+	ProblemMissingReference ProblemKind = "missing_reference"
+	// ProblemValidation is an entry with invalid values.
+	ProblemValidation ProblemKind = "validation"
+	// ProblemSkipped is an entry ignored for any other reason, such as an
+	// unsupported setting.
+	ProblemSkipped ProblemKind = "skipped"
+)
+
+// modelTables maps the model names Ghost reports in the help of import
+// problems to their tables.
+var modelTables = map[string]string{
+	"Post":       "posts",
+	"PostMeta":   "posts_meta",
+	"Tag":        "tags",
+	"PostTag":    "posts_tags",
+	"PostAuthor": "posts_authors",
+	"User":       "users",
+	"Role":       "roles",
+	"RoleUser":   "roles_users",
+	"Setting":    "settings",
+	"Member":     "members",
+	"Subscriber": "subscribers",
+	"Client":     "clients",
+	"Invite":     "invites",
+	"Webhook":    "webhooks",
+	"Product":    "products",
+	"Label":      "labels",
+}
+
+// UnmarshalJSON decodes a problem as reported by Ghost and parses its table,
+// row and kind.
+func (p *DatabaseImportProblem) UnmarshalJSON(b []byte) error {
+	type problem DatabaseImportProblem
+	if err := json.Unmarshal(b, (*problem)(p)); err != nil {
+		return err
+	}
+	p.parse()
+	return nil
+}
+
+// parse fills in the fields derived from the message, help, context and
+// error of the problem.
+func (p *DatabaseImportProblem) parse() {
+	p.Table = modelTables[p.Help]
+	if p.Table == "" && p.Help != "" && p.Help != "Meta" {
+		p.Table = strings.ToLower(p.Help)
+	}
+
+	var row struct {
+		ID   interface{} `json:"id"`
+		Slug string      `json:"slug"`
+		Key  string      `json:"key"`
+	}
+	if json.Unmarshal([]byte(p.Context), &row) == nil {
+		if row.ID != nil {
+			p.RowID = fmt.Sprint(row.ID)
+		}
+		p.RowSlug = row.Slug
+		if p.RowSlug == "" {
+			p.RowSlug = row.Key
+		}
+	}
+
+	errorType, _ := p.Err["errorType"].(string)
+	message := strings.ToLower(p.Message)
+	switch {
+	case strings.Contains(message, "duplicate"):
+		p.Kind = ProblemDuplicate
+	case errorType == "NotFoundError",
+		strings.Contains(message, "could not find"),
+		strings.Contains(message, "missing"):
+		p.Kind = ProblemMissingReference
+	case errorType == "ValidationError":
+		p.Kind = ProblemValidation
+	default:
+		p.Kind = ProblemSkipped
+	}
+}
+
+// Row describes the row of a problem for humans: its slug, id or both.
+func (p *DatabaseImportProblem) Row() string {
+	switch {
+	case p.RowID == "":
+		return p.RowSlug
+	case p.RowSlug == "":
+		return p.RowID
+	}
+	return fmt.Sprintf("%s (%s)", p.RowSlug, p.RowID)
+}
+
+// ProblemGroup holds problems of the same table, kind and message.
+type ProblemGroup struct {
+	Table    string                   `json:"table"`
+	Kind     ProblemKind              `json:"kind"`
+	Message  string                   `json:"message"`
+	Problems []*DatabaseImportProblem `json:"problems"`
+}
+
+// ProblemSummary groups import problems, ordered by table, kind and message.
+type ProblemSummary struct {
+	Groups []*ProblemGroup     `json:"groups"`
+	Kinds  map[ProblemKind]int `json:"kinds"`
+}
+
+// SummarizeProblems groups problems, as returned by Import, ImportFrom or
+// Validate, by table, kind and message.
+func SummarizeProblems(problems []*DatabaseImportProblem) *ProblemSummary {
+	s := &ProblemSummary{Groups: []*ProblemGroup{}, Kinds: make(map[ProblemKind]int)}
+	groups := make(map[string]*ProblemGroup)
+	for _, problem := range problems {
+		key := problem.Table + "\x00" + string(problem.Kind) + "\x00" + problem.Message
+		group, ok := groups[key]
+		if !ok {
+			group = &ProblemGroup{Table: problem.Table, Kind: problem.Kind, Message: problem.Message}
+			groups[key] = group
+			s.Groups = append(s.Groups, group)
+		}
+		group.Problems = append(group.Problems, problem)
+		s.Kinds[problem.Kind]++
+	}
+
+	sort.SliceStable(s.Groups, func(i, j int) bool {
+		a, b := s.Groups[i], s.Groups[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Message < b.Message
+	})
+	return s
+}
+
+func (s *ProblemSummary) String() string {
+	if len(s.Groups) == 0 {
+		return "no problems"
+	}
+
+	buf := &bytes.Buffer{}
+	for _, group := range s.Groups {
+		table := group.Table
+		if table == "" {
+			table = "(export)"
+		}
+		fmt.Fprintf(buf, "%s: %d %s: %s\n", table, len(group.Problems), group.Kind, group.Message)
+		for _, problem := range group.Problems {
+			if row := problem.Row(); row != "" {
+				fmt.Fprintf(buf, "  %s\n", row)
+			}
+		}
+	}
+	return buf.String()
+}
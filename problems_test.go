@@ -0,0 +1,90 @@
+package ghost
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testProblems = `[
+	{
+		"message": "Entry was not imported and ignored. Detected duplicated entry.",
+		"help": "Tag",
+		"context": "{\"id\":\"5e\",\"name\":\"News\",\"slug\":\"news\"}",
+		"err": {"errorType": "ValidationError"}
+	},
+	{
+		"message": "Entry was not imported and ignored. Detected duplicated entry.",
+		"help": "Tag",
+		"context": "{\"id\":\"5f\",\"slug\":\"travel\"}",
+		"err": {"errorType": "ValidationError"}
+	},
+	{
+		"message": "Entry was not imported and ignored. Could not find owner.",
+		"help": "PostTag",
+		"context": "{\"post_id\":\"1\",\"tag_id\":\"2\"}"
+	},
+	{
+		"message": "Value in [posts.title] exceeds maximum length of 255 characters.",
+		"help": "Post",
+		"context": "{\"id\":42,\"slug\":\"long\"}",
+		"err": {"errorType": "ValidationError"}
+	},
+	{
+		"message": "Permalink Setting was removed. Please configure permalinks in your routes.yaml.",
+		"help": "Setting",
+		"context": "{\"key\":\"permalinks\",\"value\":\"/:slug/\"}"
+	}
+]`
+
+func TestProblemParsing(t *testing.T) {
+	var problems []*DatabaseImportProblem
+	require.NoError(t, json.Unmarshal([]byte(testProblems), &problems))
+
+	require.Equal(t, "tags", problems[0].Table)
+	require.Equal(t, "5e", problems[0].RowID)
+	require.Equal(t, "news", problems[0].RowSlug)
+	require.Equal(t, ProblemDuplicate, problems[0].Kind)
+	require.Equal(t, "news (5e)", problems[0].Row())
+
+	require.Equal(t, "posts_tags", problems[2].Table)
+	require.Equal(t, "", problems[2].Row())
+	require.Equal(t, ProblemMissingReference, problems[2].Kind)
+
+	require.Equal(t, "42", problems[3].RowID)
+	require.Equal(t, ProblemValidation, problems[3].Kind)
+
+	require.Equal(t, "settings", problems[4].Table)
+	require.Equal(t, "permalinks", problems[4].RowSlug)
+	require.Equal(t, ProblemSkipped, problems[4].Kind)
+}
+
+func TestSummarizeProblems(t *testing.T) {
+	var problems []*DatabaseImportProblem
+	require.NoError(t, json.Unmarshal([]byte(testProblems), &problems))
+
+	summary := SummarizeProblems(problems)
+	require.Len(t, summary.Groups, 4)
+	require.Equal(t, map[ProblemKind]int{
+		ProblemDuplicate:        2,
+		ProblemMissingReference: 1,
+		ProblemValidation:       1,
+		ProblemSkipped:          1,
+	}, summary.Kinds)
+
+	require.Equal(t, "posts", summary.Groups[0].Table)
+	require.Equal(t, "tags", summary.Groups[3].Table)
+	require.Len(t, summary.Groups[3].Problems, 2)
+
+	require.Equal(t, `posts: 1 validation: Value in [posts.title] exceeds maximum length of 255 characters.
+  long (42)
+posts_tags: 1 missing_reference: Entry was not imported and ignored. Could not find owner.
+settings: 1 skipped: Permalink Setting was removed. Please configure permalinks in your routes.yaml.
+  permalinks
+tags: 2 duplicate: Entry was not imported and ignored. Detected duplicated entry.
+  news (5e)
+  travel (5f)
+`, summary.String())
+	require.Equal(t, "no problems", SummarizeProblems(nil).String())
+}
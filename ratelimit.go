@@ -0,0 +1,84 @@
+package ghost
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket that refills at rate tokens per second, up to
+// burst tokens. Waiters reserve a token up front, so they are served in the
+// order they arrive.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a rateLimiter, or nil, which never blocks, if rate
+// is not positive.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done. A nil rateLimiter
+// never blocks.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(deficit / l.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// hand back the reservation so later waiters are not penalized.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+type loginBudgetKey struct{}
+
+// withLoginBudget marks the request context as a login attempt, which is
+// limited separately from regular API calls.
+func withLoginBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loginBudgetKey{}, true)
+}
+
+func isLoginBudget(ctx context.Context) bool {
+	login, _ := ctx.Value(loginBudgetKey{}).(bool)
+	return login
+}
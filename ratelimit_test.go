@@ -0,0 +1,92 @@
+package ghost
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	l := newRateLimiter(1000, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 12; i++ {
+		require.NoError(t, l.Wait(ctx))
+	}
+	// the burst is free, the remaining ten tokens take ~1ms each.
+	require.True(t, time.Since(start) >= 9*time.Millisecond)
+}
+
+func TestRateLimiter_Wait_canceled(t *testing.T) {
+	l := newRateLimiter(0.001, 1)
+	require.NoError(t, l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, l.Wait(ctx))
+}
+
+func TestAdminClient_Do_rateLimited(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(BaseAdminPath+"session/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc(BaseAdminPath+"posts/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"posts": []}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewAdminClient(server.URL, &http.Client{},
+		WithRateLimit(0.001, 1), WithLoginRateLimit(0.001, 1))
+	require.NoError(t, err)
+
+	// the login budget is independent of the API budget.
+	_, err = client.Session.Create("u", "p")
+	require.NoError(t, err)
+	_, _, err = client.Posts.List(nil)
+	require.NoError(t, err)
+
+	req, err := client.NewRequest("GET", "posts/", nil)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = client.Do(req.WithContext(ctx), nil)
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRateLimiter_noLimit(t *testing.T) {
+	// rates of zero or less mean no limit, rather than waiting forever or
+	// draining the bucket.
+	require.Nil(t, newRateLimiter(0, 1))
+	require.Nil(t, newRateLimiter(-1, 1))
+}
+
+func TestAdminClient_WithContext(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(BaseAdminPath+"posts/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"posts": []}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewAdminClient(server.URL, &http.Client{}, WithRateLimit(0.001, 1))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	withCtx := client.WithContext(ctx)
+	require.True(t, withCtx.Posts.client == withCtx)
+	require.True(t, client.Posts.client == client)
+
+	// the copy shares the budget of the client, and gives up waiting once
+	// its context is done.
+	_, _, err = client.Posts.List(nil)
+	require.NoError(t, err)
+	_, _, err = withCtx.Posts.List(nil)
+	require.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,168 @@
+package ghost
+
+import (
+	"errors"
+	"fmt"
+)
+
+// redirectEditAttempts bounds how often Edit re-applies its edits when the
+// redirects change on the server while editing.
+const redirectEditAttempts = 3
+
+// ErrRedirectsChanged is returned by Edit when the redirects kept changing on
+// the server while editing.
+var ErrRedirectsChanged = errors.New("redirects changed on the server while editing")
+
+// RedirectEdit is an edit of a set of redirects, returning the edited set.
+// It must not modify the given redirects.
+type RedirectEdit func(redirects []*Redirect) ([]*Redirect, error)
+
+func findRedirect(redirects []*Redirect, from string) int {
+	for i, r := range redirects {
+		if r != nil && r.From == from {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddRedirect appends r, failing if a redirect from the same From exists.
+func AddRedirect(r *Redirect) RedirectEdit {
+	return func(redirects []*Redirect) ([]*Redirect, error) {
+		if findRedirect(redirects, r.From) >= 0 {
+			return nil, fmt.Errorf("redirect from %q already exists", r.From)
+		}
+		edited := append([]*Redirect{}, redirects...)
+		return append(edited, r), nil
+	}
+}
+
+// RemoveRedirect removes the redirect from from, failing if there is none.
+func RemoveRedirect(from string) RedirectEdit {
+	return func(redirects []*Redirect) ([]*Redirect, error) {
+		i := findRedirect(redirects, from)
+		if i < 0 {
+			return nil, fmt.Errorf("no redirect from %q", from)
+		}
+		edited := append([]*Redirect{}, redirects[:i]...)
+		return append(edited, redirects[i+1:]...), nil
+	}
+}
+
+// ReplaceRedirect replaces the redirect from from with r, keeping its
+// position, failing if there is none.
+func ReplaceRedirect(from string, r *Redirect) RedirectEdit {
+	return func(redirects []*Redirect) ([]*Redirect, error) {
+		i := findRedirect(redirects, from)
+		if i < 0 {
+			return nil, fmt.Errorf("no redirect from %q", from)
+		}
+		edited := append([]*Redirect{}, redirects...)
+		edited[i] = r
+		return edited, nil
+	}
+}
+
+// RedirectVerifyError is returned by Edit when the redirects downloaded after
+// uploading differ from those uploaded. The previous redirects were uploaded
+// again, unless RollbackErr is set.
+type RedirectVerifyError struct {
+	Uploaded    []*Redirect
+	Downloaded  []*Redirect
+	RollbackErr error
+}
+
+func (e *RedirectVerifyError) Error() string {
+	msg := "redirects on the server differ from those uploaded"
+	if e.RollbackErr != nil {
+		return msg + "; rollback failed: " + e.RollbackErr.Error()
+	}
+	return msg + "; rolled back"
+}
+
+func equalRedirects(a, b []*Redirect) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == nil || b[i] == nil {
+			// null entries in a redirects file decode to nil.
+			if a[i] != b[i] {
+				return false
+			}
+			continue
+		}
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutNull returns redirects without their nil entries, which null entries
+// in a redirects file decode to.
+func withoutNull(redirects []*Redirect) []*Redirect {
+	kept := make([]*Redirect, 0, len(redirects))
+	for _, r := range redirects {
+		if r != nil {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// Edit applies edits to the current redirects and uploads the result,
+// returning the redirects uploaded.
+//
+// Ghost has no way of updating redirects conditionally, so Edit narrows the
+// window for lost updates instead: right before uploading it downloads the
+// redirects again, and if they changed since, applies the edits afresh to
+// the changed redirects, returning ErrRedirectsChanged if they keep changing.
+// Null entries of the redirects are dropped when uploading.
+// After uploading it downloads the redirects to verify them, uploading the
+// previous redirects again if they differ and returning a
+// *RedirectVerifyError.
+func (s *AdminRedirectsService) Edit(edits ...RedirectEdit) ([]*Redirect, *Response, error) {
+	current, resp, err := s.Download()
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for attempt := 0; attempt < redirectEditAttempts; attempt++ {
+		edited := current
+		for _, edit := range edits {
+			edited, err = edit(edited)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		edited = withoutNull(edited)
+
+		latest, resp, err := s.Download()
+		if err != nil {
+			return nil, resp, err
+		}
+		if !equalRedirects(current, latest) {
+			current = latest
+			continue
+		}
+
+		if resp, err := s.Upload(edited); err != nil {
+			return nil, resp, err
+		}
+
+		uploaded, resp, err := s.Download()
+		if err != nil {
+			return nil, resp, err
+		}
+		if equalRedirects(edited, uploaded) {
+			return edited, resp, nil
+		}
+
+		// the previous redirects are restored even if they fail linting.
+		verifyErr := &RedirectVerifyError{Uploaded: edited, Downloaded: uploaded}
+		resp, verifyErr.RollbackErr = s.upload(current)
+		return nil, resp, verifyErr
+	}
+	return nil, nil, ErrRedirectsChanged
+}
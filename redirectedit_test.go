@@ -0,0 +1,161 @@
+package ghost
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// redirectsServer serves redirects/json from memory. Before serving each
+// download, onDownload may change the stored redirects, and uploads pass
+// through onUpload.
+type redirectsServer struct {
+	mu         sync.Mutex
+	redirects  []*Redirect
+	downloads  int
+	uploads    [][]*Redirect
+	onDownload func(n int, redirects []*Redirect) []*Redirect
+	onUpload   func(redirects []*Redirect) []*Redirect
+}
+
+func (s *redirectsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == "GET" {
+		s.downloads++
+		if s.onDownload != nil {
+			s.redirects = s.onDownload(s.downloads, s.redirects)
+		}
+		json.NewEncoder(w).Encode(s.redirects)
+		return
+	}
+
+	file, _, err := r.FormFile("redirects")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, _ := ioutil.ReadAll(file)
+	var redirects []*Redirect
+	json.Unmarshal(b, &redirects)
+	s.uploads = append(s.uploads, redirects)
+	if s.onUpload != nil {
+		redirects = s.onUpload(redirects)
+	}
+	s.redirects = redirects
+}
+
+func TestRedirectsService_Edit(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	server := &redirectsServer{redirects: []*Redirect{{From: "/a/", To: "/b/"}, {From: "/c/", To: "/d/"}}}
+	mux.Handle(BaseAdminPath+"redirects/json", server)
+
+	redirects, _, err := client.Redirects.Edit(
+		AddRedirect(&Redirect{From: "/e/", To: "/f/", Permanent: true}),
+		RemoveRedirect("/a/"),
+		ReplaceRedirect("/c/", &Redirect{From: "/c/", To: "/g/"}),
+	)
+	require.NoError(t, err)
+	want := []*Redirect{{From: "/c/", To: "/g/"}, {From: "/e/", To: "/f/", Permanent: true}}
+	require.Equal(t, want, redirects)
+	require.Equal(t, want, server.redirects)
+
+	_, _, err = client.Redirects.Edit(RemoveRedirect("/missing/"))
+	require.Error(t, err)
+}
+
+func TestRedirectsService_EditNullEntry(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	// a null entry in the redirects file is dropped.
+	server := &redirectsServer{redirects: []*Redirect{nil, {From: "/a/", To: "/b/"}}}
+	mux.Handle(BaseAdminPath+"redirects/json", server)
+
+	redirects, _, err := client.Redirects.Edit(RemoveRedirect("/a/"), AddRedirect(&Redirect{From: "/c/", To: "/d/"}))
+	require.NoError(t, err)
+	require.Equal(t, []*Redirect{{From: "/c/", To: "/d/"}}, redirects)
+	require.Equal(t, [][]*Redirect{{{From: "/c/", To: "/d/"}}}, server.uploads)
+
+	require.False(t, equalRedirects([]*Redirect{nil}, []*Redirect{{From: "/a/"}}))
+	require.False(t, equalRedirects([]*Redirect{{From: "/a/"}}, []*Redirect{nil}))
+}
+
+func TestRedirectsService_EditNullEntryLint(t *testing.T) {
+	server := &redirectsServer{redirects: []*Redirect{{From: "/a/", To: "/b/"}, nil}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	// the null entry would fail linting, were it uploaded.
+	client, err := NewAdminClient(ts.URL, &http.Client{}, WithRedirectLint(nil))
+	require.NoError(t, err)
+
+	redirects, _, err := client.Redirects.Edit(AddRedirect(&Redirect{From: "/c/", To: "/d/"}))
+	require.NoError(t, err)
+	require.Equal(t, []*Redirect{{From: "/a/", To: "/b/"}, {From: "/c/", To: "/d/"}}, redirects)
+	require.Equal(t, redirects, server.redirects)
+}
+
+func TestRedirectsService_EditConcurrentChange(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	// someone else adds a redirect between the first two downloads.
+	server := &redirectsServer{
+		redirects: []*Redirect{{From: "/a/", To: "/b/"}},
+		onDownload: func(n int, redirects []*Redirect) []*Redirect {
+			if n == 2 {
+				return append(redirects, &Redirect{From: "/x/", To: "/y/"})
+			}
+			return redirects
+		},
+	}
+	mux.Handle(BaseAdminPath+"redirects/json", server)
+
+	redirects, _, err := client.Redirects.Edit(AddRedirect(&Redirect{From: "/c/", To: "/d/"}))
+	require.NoError(t, err)
+	require.Equal(t, []*Redirect{
+		{From: "/a/", To: "/b/"},
+		{From: "/x/", To: "/y/"},
+		{From: "/c/", To: "/d/"},
+	}, redirects)
+	require.Len(t, server.uploads, 1)
+
+	server.onDownload = func(n int, redirects []*Redirect) []*Redirect {
+		return append(redirects, &Redirect{From: "/z/", To: "/"})
+	}
+	_, _, err = client.Redirects.Edit(AddRedirect(&Redirect{From: "/e/", To: "/f/"}))
+	require.Equal(t, ErrRedirectsChanged, err)
+	require.Len(t, server.uploads, 1)
+}
+
+func TestRedirectsService_EditRollback(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	previous := []*Redirect{{From: "/a/", To: "/b/"}}
+	server := &redirectsServer{redirects: previous}
+	// the first upload is mangled, e.g. by a proxy stripping fields.
+	server.onUpload = func(redirects []*Redirect) []*Redirect {
+		if len(server.uploads) == 1 {
+			return redirects[:1]
+		}
+		return redirects
+	}
+	mux.Handle(BaseAdminPath+"redirects/json", server)
+
+	_, _, err := client.Redirects.Edit(AddRedirect(&Redirect{From: "/c/", To: "/d/"}))
+	verifyErr, ok := err.(*RedirectVerifyError)
+	require.True(t, ok, "%v", err)
+	require.NoError(t, verifyErr.RollbackErr)
+	require.Len(t, verifyErr.Downloaded, 1)
+	require.Len(t, server.uploads, 2)
+	require.Equal(t, previous, server.redirects)
+}
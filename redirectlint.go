@@ -0,0 +1,330 @@
+package ghost
+
+import (
+	"fmt"
+	"net/url"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// LintKind classifies a LintProblem.
+type LintKind string
+
+// Kinds of redirect lint problems.
+const (
+	// LintInvalid is a redirect Ghost would reject, such as one with an
+	// invalid regular expression.
+	LintInvalid LintKind = "invalid"
+	// LintDuplicate is a redirect whose From compiles to the same pattern as
+	// an earlier one.
+	LintDuplicate LintKind = "duplicate"
+	// LintUnreachable is a redirect whose paths are all matched by earlier
+	// redirects.
+	LintUnreachable LintKind = "unreachable"
+	// LintChain is a redirect whose target is redirected again.
+	LintChain LintKind = "chain"
+	// LintCycle is a redirect whose target eventually redirects back to it.
+	LintCycle LintKind = "cycle"
+	// LintNotFound is a redirect whose final target does not exist.
+	LintNotFound LintKind = "not_found"
+)
+
+// LintProblem is a problem found with a redirect, at Index in the redirects.
+type LintProblem struct {
+	Kind     LintKind
+	Index    int
+	Redirect *Redirect
+	Message  string
+}
+
+func (p *LintProblem) String() string {
+	if p.Redirect == nil {
+		return fmt.Sprintf("redirect %d: %s", p.Index, p.Message)
+	}
+	return fmt.Sprintf("redirect %d from %q: %s", p.Index, p.Redirect.From, p.Message)
+}
+
+// LintOptions configure LintRedirects.
+type LintOptions struct {
+	// Exists, if set, reports whether the final target of a redirect exists,
+	// given as the location Ghost would redirect to.
+	Exists func(location string) (bool, error)
+}
+
+// LintError is returned by Upload when the redirects fail linting, see
+// WithRedirectLint.
+type LintError struct {
+	Problems []*LintProblem
+}
+
+func (e *LintError) Error() string {
+	msg := fmt.Sprintf("%d redirect lint problem(s)", len(e.Problems))
+	if len(e.Problems) > 0 {
+		msg += ": " + e.Problems[0].String()
+	}
+	return msg
+}
+
+// maxLintSamples bounds the number of paths sampled from each redirect.
+const maxLintSamples = 32
+
+// LintRedirects checks redirects for invalid and duplicate From patterns,
+// unreachable redirects, chains and cycles, and, if opts.Exists is set,
+// targets that do not exist.
+//
+// Redirects are followed and compared using paths sampled from their From
+// patterns, so the checks are heuristic: a redirect is reported unreachable
+// only if every sampled path is matched by an earlier redirect, and targets
+// using group references are not checked for existence, as the sampled
+// paths do not make for meaningful targets.
+func LintRedirects(redirects []*Redirect, opts *LintOptions) ([]*LintProblem, error) {
+	if opts == nil {
+		opts = &LintOptions{}
+	}
+
+	var problems []*LintProblem
+	report := func(kind LintKind, i int, format string, args ...interface{}) {
+		problems = append(problems, &LintProblem{Kind: kind, Index: i, Redirect: redirects[i], Message: fmt.Sprintf(format, args...)})
+	}
+
+	// rules holds the valid redirects, along with their index and sampled
+	// paths, in order.
+	type lintRule struct {
+		index   int
+		rule    *RedirectRule
+		samples []string
+	}
+	var rules []*lintRule
+	patterns := make(map[string]int)
+	for i, r := range redirects {
+		if r == nil {
+			// null entries in a redirects file decode to nil.
+			report(LintInvalid, i, "null redirect")
+			continue
+		}
+		rule, err := CompileRedirect(r)
+		if err != nil {
+			report(LintInvalid, i, "%v", err)
+			continue
+		}
+		if first, ok := patterns[rule.Pattern()]; ok {
+			report(LintDuplicate, i, "same pattern as redirect %d", first)
+			continue
+		}
+		patterns[rule.Pattern()] = i
+		rules = append(rules, &lintRule{index: i, rule: rule, samples: samplePaths(rule)})
+	}
+
+	compiled := &RedirectRules{}
+	for _, lr := range rules {
+		compiled.Rules = append(compiled.Rules, lr.rule)
+	}
+
+	for n, lr := range rules {
+		// unreachable unless some sampled path falls through the earlier
+		// rules.
+		shadowed := len(lr.samples) > 0
+		shadowedBy := -1
+		for _, sample := range lr.samples {
+			match, err := compiled.Resolve(sample)
+			if err != nil {
+				return nil, err
+			}
+			if match == nil || match.Index >= n {
+				shadowed = false
+				break
+			}
+			shadowedBy = rules[match.Index].index
+		}
+		if shadowed {
+			report(LintUnreachable, lr.index, "every path is matched by an earlier redirect, such as %d", shadowedBy)
+			continue
+		}
+
+		if len(lr.samples) == 0 {
+			continue
+		}
+		location, err := lr.rule.Location(lr.samples[0])
+		if err != nil {
+			return nil, err
+		}
+
+		// follow the target through the rules while it stays on the site.
+		path := []int{lr.index}
+		visited := map[int]bool{n: true}
+		cycle := false
+		for len(path) <= len(rules) {
+			target, err := url.Parse(location)
+			if err != nil || target.Host != "" {
+				break
+			}
+			match, err := compiled.Resolve(location)
+			if err != nil {
+				return nil, err
+			}
+			if match == nil {
+				break
+			}
+			path = append(path, rules[match.Index].index)
+			if visited[match.Index] {
+				cycle = true
+				break
+			}
+			visited[match.Index] = true
+			location = match.Location
+		}
+
+		switch {
+		case cycle:
+			report(LintCycle, lr.index, "redirect loop through %s", describePath(path))
+		case len(path) > 1:
+			report(LintChain, lr.index, "target %s is redirected again, through %s", location, describePath(path))
+		}
+		if cycle || opts.Exists == nil || strings.Contains(lr.rule.Redirect.To, "$") {
+			continue
+		}
+
+		exists, err := opts.Exists(location)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			report(LintNotFound, lr.index, "target %s does not exist", location)
+		}
+	}
+
+	// problems are ordered by the index of their redirect, keeping the order
+	// of problems with the same redirect.
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Index < problems[j].Index
+	})
+	return problems, nil
+}
+
+func describePath(path []int) string {
+	s := make([]string, len(path))
+	for i, index := range path {
+		s[i] = fmt.Sprint(index)
+	}
+	return strings.Join(s, " -> ")
+}
+
+// samplePaths returns paths matched by rule, covering each alternative of
+// its pattern, up to maxLintSamples.
+func samplePaths(rule *RedirectRule) []string {
+	re, err := syntax.Parse(rule.Pattern(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, sample := range samples(re.Simplify()) {
+		path := sample
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		if !seen[path] && rule.MatchPath(path) {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// classSamples returns up to two runes of the character class ranges,
+// preferring letters and digits and avoiding characters with a special
+// meaning in URLs.
+func classSamples(ranges []rune) []rune {
+	var out []rune
+	add := func(r rune) {
+		if len(out) < 2 && (len(out) == 0 || out[0] != r) {
+			out = append(out, r)
+		}
+	}
+	for _, pref := range [][2]rune{{'a', 'z'}, {'0', '9'}, {'A', 'Z'}} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			lo, hi := ranges[i], ranges[i+1]
+			if lo < pref[0] {
+				lo = pref[0]
+			}
+			if hi > pref[1] {
+				hi = pref[1]
+			}
+			if lo <= hi {
+				add(lo)
+				add(hi)
+			}
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r < 0x7f; r++ {
+			if r > ' ' && !strings.ContainsRune("?#%", r) {
+				add(r)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// samples returns strings matched by re: one for each alternative, and for
+// character classes and repetitions, their first and last options.
+func samples(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCharClass:
+		var out []string
+		for _, r := range classSamples(re.Rune) {
+			out = append(out, string(r))
+		}
+		return out
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []string{"a"}
+	case syntax.OpCapture:
+		return samples(re.Sub[0])
+	case syntax.OpStar, syntax.OpQuest:
+		return append([]string{""}, samples(re.Sub[0])...)
+	case syntax.OpPlus:
+		return samples(re.Sub[0])
+	case syntax.OpRepeat:
+		sub := samples(re.Sub[0])
+		var out []string
+		for _, s := range sub {
+			out = append(out, strings.Repeat(s, re.Min))
+		}
+		return out
+	case syntax.OpConcat:
+		out := []string{""}
+		for _, sub := range re.Sub {
+			var next []string
+			for _, prefix := range out {
+				for _, s := range samples(sub) {
+					if len(next) < maxLintSamples {
+						next = append(next, prefix+s)
+					}
+				}
+			}
+			out = next
+		}
+		return out
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			out = append(out, samples(sub)...)
+		}
+		if len(out) > maxLintSamples {
+			out = out[:maxLintSamples]
+		}
+		return out
+	case syntax.OpNoMatch:
+		return nil
+	}
+	// empty matches, anchors and word boundaries.
+	return []string{""}
+}
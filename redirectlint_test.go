@@ -0,0 +1,84 @@
+package ghost
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintRedirects(t *testing.T) {
+	redirects := []*Redirect{
+		{From: "^/blog/(.*)", To: "/$1"},
+		{From: "^/blog/hello", To: "/hello/"},
+		{From: "^/a/", To: "/b/"},
+		{From: "^/b/", To: "/c/"},
+		{From: "^/loop-a", To: "/loop-b"},
+		{From: "^/loop-b", To: "/loop-a"},
+		{From: "^/a", To: "/elsewhere/"},
+		{From: "/(?<=x)", To: "/y/"},
+		{From: "^/(news|updates)/", To: "https://news.example.com/"},
+		{From: "^/updates", To: "/gone/"},
+		{From: "^/(news|blog|other)/", To: "/kept/"},
+	}
+
+	exists := map[string]bool{"/c/": true, "/kept/": true, "https://news.example.com/": true}
+	problems, err := LintRedirects(redirects, &LintOptions{
+		Exists: func(location string) (bool, error) {
+			return exists[location], nil
+		},
+	})
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, fmt.Sprintf("%d %s", p.Index, p.Kind))
+	}
+	require.Equal(t, []string{
+		"1 unreachable",
+		"2 chain",
+		"4 cycle",
+		"5 cycle",
+		"6 duplicate",
+		"7 invalid",
+		"9 unreachable",
+	}, got)
+	require.Equal(t, "redirect loop through 4 -> 5 -> 4", problems[2].Message)
+
+	problems, err = LintRedirects([]*Redirect{{From: "^/x/", To: "/missing/"}}, &LintOptions{
+		Exists: func(location string) (bool, error) { return false, nil },
+	})
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	require.Equal(t, LintNotFound, problems[0].Kind)
+}
+
+func TestLintRedirects_null(t *testing.T) {
+	problems, err := LintRedirects([]*Redirect{{From: "/a/", To: "/b/"}, nil}, nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	require.Equal(t, LintInvalid, problems[0].Kind)
+	require.Equal(t, 1, problems[0].Index)
+	require.Equal(t, "redirect 1: null redirect", problems[0].String())
+}
+
+func TestRedirectsService_UploadLint(t *testing.T) {
+	uploaded := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded = true
+	}))
+	defer server.Close()
+
+	client, err := NewAdminClient(server.URL, &http.Client{}, WithRedirectLint(nil))
+	require.NoError(t, err)
+
+	_, err = client.Redirects.Upload([]*Redirect{{From: "^/a", To: "/a/"}})
+	require.IsType(t, &LintError{}, err)
+	require.False(t, uploaded)
+
+	_, err = client.Redirects.Upload([]*Redirect{{From: "^/a", To: "/b/"}})
+	require.NoError(t, err)
+	require.True(t, uploaded)
+}
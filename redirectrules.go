@@ -0,0 +1,292 @@
+package ghost
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// caseInsensitiveRedirect matches a From enclosed in /.../i, which Ghost
+// matches case insensitively.
+var caseInsensitiveRedirect = regexp.MustCompile(`^/.*/i$`)
+
+// RedirectRule is a Redirect compiled the way Ghost compiles it.
+type RedirectRule struct {
+	Redirect *Redirect
+	pattern  *regexp.Regexp
+}
+
+// RedirectError is a redirect Ghost would reject, with its index in the
+// redirects.
+type RedirectError struct {
+	Index    int
+	Redirect *Redirect
+	Err      error
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("redirect %d from %q: %v", e.Index, e.Redirect.From, e.Err)
+}
+
+// CompileRedirect compiles a single redirect as Ghost does: a From enclosed
+// in /.../i is matched case insensitively, with the enclosing characters
+// removed; a trailing slash is made optional; and unless From ends in $ it is
+// anchored to the end of the path. From is not anchored to the start of the
+// path unless it starts with ^.
+//
+// Ghost evaluates From as a JavaScript regular expression, whereas it is
+// compiled here with Go's regexp package, so patterns relying on features
+// unsupported by Go, like lookarounds and backreferences, fail to compile.
+func CompileRedirect(r *Redirect) (*RedirectRule, error) {
+	if r.From == "" || r.To == "" {
+		return nil, fmt.Errorf("redirect is missing from or to")
+	}
+
+	from := r.From
+	flags := ""
+	if caseInsensitiveRedirect.MatchString(from) {
+		from = from[1 : len(from)-2]
+		flags = "(?i)"
+	}
+	from = strings.TrimSuffix(from, "/")
+	if !strings.HasSuffix(from, "$") {
+		from += `/?$`
+	}
+
+	pattern, err := regexp.Compile(flags + from)
+	if err != nil {
+		return nil, err
+	}
+	return &RedirectRule{Redirect: r, pattern: pattern}, nil
+}
+
+// Pattern returns the regular expression the rule matches paths against.
+func (rule *RedirectRule) Pattern() string {
+	return rule.pattern.String()
+}
+
+// MatchPath reports whether the rule matches the escaped path.
+func (rule *RedirectRule) MatchPath(path string) bool {
+	return rule.pattern.MatchString(path)
+}
+
+// Status is the status code of the rule's redirects.
+func (rule *RedirectRule) Status() int {
+	if rule.Redirect.Permanent {
+		return http.StatusMovedPermanently
+	}
+	return http.StatusFound
+}
+
+// Location returns where the rule redirects requestURL, a path with an
+// optional query, as Ghost does: the part of the path matched by From is
+// replaced with the path of To, expanding $1 style references to groups, and
+// the queries of the request and To are merged, with To taking precedence.
+// It returns "" if the rule does not match.
+func (rule *RedirectRule) Location(requestURL string) (string, error) {
+	current, err := url.Parse(requestURL)
+	if err != nil {
+		return "", err
+	}
+	to, err := url.Parse(rule.Redirect.To)
+	if err != nil {
+		return "", err
+	}
+
+	path := current.EscapedPath()
+	match := rule.pattern.FindStringSubmatchIndex(path)
+	if match == nil {
+		return "", nil
+	}
+
+	toPath := to.EscapedPath()
+	if toPath == "" && to.Host != "" {
+		toPath = "/"
+	}
+	path = path[:match[0]] + expandReplacement(toPath, path, match) + path[match[1]:]
+
+	location := ""
+	if to.Scheme != "" {
+		location = to.Scheme + ":"
+	}
+	if to.Host != "" {
+		location += "//" + to.Host
+	}
+	location += path
+	if query := mergeQueries(current.RawQuery, to.RawQuery); query != "" {
+		location += "?" + query
+	}
+	if to.Fragment != "" {
+		location += "#" + to.EscapedFragment()
+	}
+	return location, nil
+}
+
+// expandReplacement expands the replacement patterns of JavaScript's
+// String.prototype.replace in template: $$, $&, $`, $' and $n or $nn, against
+// the match of src.
+func expandReplacement(template, src string, match []int) string {
+	groups := len(match)/2 - 1
+	group := func(n int) string {
+		if match[2*n] < 0 {
+			return ""
+		}
+		return src[match[2*n]:match[2*n+1]]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i+1 == len(template) {
+			b.WriteByte(template[i])
+			continue
+		}
+		switch next := template[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '&':
+			b.WriteString(group(0))
+			i++
+		case next == '`':
+			b.WriteString(src[:match[0]])
+			i++
+		case next == '\'':
+			b.WriteString(src[match[1]:])
+			i++
+		case next >= '0' && next <= '9':
+			// two digits are taken when they name an existing group.
+			if i+2 < len(template) && template[i+2] >= '0' && template[i+2] <= '9' {
+				if n, _ := strconv.Atoi(template[i+1 : i+3]); n >= 1 && n <= groups {
+					b.WriteString(group(n))
+					i += 2
+					continue
+				}
+			}
+			if n := int(next - '0'); n >= 1 && n <= groups {
+				b.WriteString(group(n))
+				i++
+				continue
+			}
+			b.WriteByte('$')
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String()
+}
+
+type queryParam struct {
+	key    string
+	values []string
+}
+
+// mergeQueries merges the query to into current, as Ghost does with Node's
+// querystring module: keys keep their first position, values of to replace
+// those of current.
+func mergeQueries(current, to string) string {
+	var params []*queryParam
+	index := make(map[string]*queryParam)
+	for _, query := range []string{current, to} {
+		seen := make(map[string]bool)
+		for _, pair := range strings.Split(query, "&") {
+			if pair == "" {
+				continue
+			}
+			kv := strings.SplitN(pair, "=", 2)
+			key := unescapeQuery(kv[0])
+			value := ""
+			if len(kv) == 2 {
+				value = unescapeQuery(kv[1])
+			}
+
+			param, ok := index[key]
+			if !ok {
+				param = &queryParam{key: key}
+				index[key] = param
+				params = append(params, param)
+			}
+			if !seen[key] {
+				param.values = nil
+				seen[key] = true
+			}
+			param.values = append(param.values, value)
+		}
+	}
+
+	var pairs []string
+	for _, param := range params {
+		for _, value := range param.values {
+			pairs = append(pairs, escapeQuery(param.key)+"="+escapeQuery(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func unescapeQuery(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// escapeQuery escapes s like Node's querystring.escape.
+func escapeQuery(s string) string {
+	const unreserved = "-_.!~*'()"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(unreserved, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// RedirectRules are compiled redirects, matched in order.
+type RedirectRules struct {
+	Rules []*RedirectRule
+}
+
+// CompileRedirects compiles redirects as Ghost does, see CompileRedirect. The
+// error, if any, is a *RedirectError for the first redirect that failed.
+func CompileRedirects(redirects []*Redirect) (*RedirectRules, error) {
+	rules := &RedirectRules{}
+	for i, r := range redirects {
+		rule, err := CompileRedirect(r)
+		if err != nil {
+			return nil, &RedirectError{Index: i, Redirect: r, Err: err}
+		}
+		rules.Rules = append(rules.Rules, rule)
+	}
+	return rules, nil
+}
+
+// RedirectMatch is the result of resolving a request against RedirectRules.
+type RedirectMatch struct {
+	// Index is the index of Rule within the rules.
+	Index    int
+	Rule     *RedirectRule
+	Location string
+	Status   int
+}
+
+// Resolve returns the redirect Ghost would serve for requestURL, a path with
+// an optional query, from the first rule matching its path, or nil if no rule
+// matches.
+func (rules *RedirectRules) Resolve(requestURL string) (*RedirectMatch, error) {
+	for i, rule := range rules.Rules {
+		location, err := rule.Location(requestURL)
+		if err != nil {
+			return nil, err
+		}
+		if location != "" {
+			return &RedirectMatch{Index: i, Rule: rule, Location: location, Status: rule.Status()}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+package ghost
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileRedirect(t *testing.T) {
+	tests := []struct {
+		from    string
+		pattern string
+	}{
+		{"/old/", `/old/?$`},
+		{"^/old$", `^/old$`},
+		{`/^\/Blog\/(.*)/i`, `(?i)^\/Blog\/(.*)/?$`},
+		{"/tag/[a-z]+", `/tag/[a-z]+/?$`},
+	}
+	for _, test := range tests {
+		rule, err := CompileRedirect(&Redirect{From: test.from, To: "/new/"})
+		require.NoError(t, err, test.from)
+		require.Equal(t, test.pattern, rule.Pattern(), test.from)
+	}
+
+	_, err := CompileRedirect(&Redirect{From: "/(?!x)", To: "/"})
+	require.Error(t, err)
+	_, err = CompileRedirect(&Redirect{From: "/a"})
+	require.Error(t, err)
+}
+
+func TestRedirectRules_Resolve(t *testing.T) {
+	rules, err := CompileRedirects([]*Redirect{
+		{From: "^/old-post/", To: "/new-post/", Permanent: true},
+		{From: `/^\/Blog\/(.*)/i`, To: "/$1"},
+		{From: "^/search", To: "https://elsewhere.com/find?src=ghost#top"},
+		{From: "^/price/([0-9]+)", To: "/cost/$1$$0/$10/$&"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		url      string
+		location string
+		status   int
+	}{
+		{"/old-post", "/new-post/", http.StatusMovedPermanently},
+		{"/old-post/?ref=mail", "/new-post/?ref=mail", http.StatusMovedPermanently},
+		{"/blog/2020/hello/", "/2020/hello/", http.StatusFound},
+		{"/search?q=a+b&src=x", "https://elsewhere.com/find?q=a%20b&src=ghost#top", http.StatusFound},
+		{"/price/12", "/cost/12$0/120//price/12", http.StatusFound},
+	}
+	for _, test := range tests {
+		match, err := rules.Resolve(test.url)
+		require.NoError(t, err, test.url)
+		require.NotNil(t, match, test.url)
+		require.Equal(t, test.location, match.Location, test.url)
+		require.Equal(t, test.status, match.Status, test.url)
+	}
+
+	match, err := rules.Resolve("/old-post/extra")
+	require.NoError(t, err)
+	require.Nil(t, match)
+
+	_, err = CompileRedirects([]*Redirect{{From: "/a", To: "/b"}, {From: "/(", To: "/c"}})
+	require.Equal(t, 1, err.(*RedirectError).Index)
+}
@@ -8,30 +8,41 @@ import (
 // AdminRedirectsService handles downloading and uploading the redirects.json file.
 type AdminRedirectsService adminService
 
-// Redirect is a single redirect entry.
+// Redirect is a single redirect entry. From is a regular expression matched
+// against request paths, see CompileRedirects.
 type Redirect struct {
-	From string `json:"from"`
-	To   string `json:"to"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Permanent bool   `json:"permanent,omitempty"`
 }
 
-// Download fetches the redirectsq
-func (s *AdminRedirectsService) Download() ([]*Redirect, error) {
+// Download fetches the redirects.
+func (s *AdminRedirectsService) Download() ([]*Redirect, *Response, error) {
 	req, err := s.client.NewRequest("GET", "redirects/json", nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var redirects []*Redirect
-	_, err = s.client.Do(req, &redirects)
+	resp, err := s.client.Do(req, &redirects)
 	if err != nil {
-		return nil, err
+		return nil, newResponse(resp), err
 	}
 
-	return redirects, nil
+	return redirects, newResponse(resp), nil
+}
+
+// Upload uploads the redirects. If the client was created WithRedirectLint,
+// redirects failing linting are not uploaded and a *LintError is returned.
+func (s *AdminRedirectsService) Upload(redirects []*Redirect) (*Response, error) {
+	if err := s.lint(redirects); err != nil {
+		return nil, err
+	}
+	return s.upload(redirects)
 }
 
-// Upload uploads the redirects.
-func (s *AdminRedirectsService) Upload(redirects []*Redirect) error {
+// upload uploads the redirects without linting them.
+func (s *AdminRedirectsService) upload(redirects []*Redirect) (*Response, error) {
 	redirectsWriter := func(mpw *multipart.Writer) error {
 		part, err := createFormFile(mpw, "redirects", "redirects.json", "application/json")
 		if err != nil {
@@ -44,9 +55,24 @@ func (s *AdminRedirectsService) Upload(redirects []*Redirect) error {
 
 	req, err := s.client.NewUploadRequest("redirects/json", redirectsWriter, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = s.client.Do(req, nil)
-	return err
+	resp, err := s.client.Do(req, nil)
+	return newResponse(resp), err
+}
+
+// lint lints redirects if the client was created WithRedirectLint.
+func (s *AdminRedirectsService) lint(redirects []*Redirect) error {
+	if s.client.redirectLint == nil {
+		return nil
+	}
+	problems, err := LintRedirects(redirects, s.client.redirectLint)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &LintError{Problems: problems}
+	}
+	return nil
 }
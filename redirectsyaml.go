@@ -0,0 +1,246 @@
+package ghost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// RedirectFormat is a file format of redirects.
+type RedirectFormat string
+
+// Formats of redirect files.
+const (
+	// RedirectsJSON is a JSON array of redirects, the only format of Ghost 3.
+	RedirectsJSON RedirectFormat = "json"
+	// RedirectsYAML is the redirects.yaml format of Ghost 4 and later: maps
+	// from From to To under the keys 301, for permanent redirects, and 302.
+	RedirectsYAML RedirectFormat = "yaml"
+)
+
+// MarshalRedirects encodes redirects in format. In the YAML format permanent
+// and temporary redirects are grouped, and Ghost matches all temporary
+// redirects before permanent ones, so the order of redirects is only kept
+// within each group; see UnmarshalRedirects. Each group maps from From, so
+// redirects from the same From in a group are an error.
+func MarshalRedirects(redirects []*Redirect, format RedirectFormat) ([]byte, error) {
+	switch format {
+	case RedirectsJSON:
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if redirects == nil {
+			redirects = []*Redirect{}
+		}
+		if err := enc.Encode(redirects); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case RedirectsYAML:
+		var permanent, temporary yaml.MapSlice
+		// a From can only be a key once in each map.
+		seen := map[bool]map[string]bool{true: {}, false: {}}
+		for _, r := range redirects {
+			if seen[r.Permanent][r.From] {
+				return nil, fmt.Errorf("duplicate redirect from %q cannot be encoded as YAML", r.From)
+			}
+			seen[r.Permanent][r.From] = true
+			item := yaml.MapItem{Key: r.From, Value: r.To}
+			if r.Permanent {
+				permanent = append(permanent, item)
+			} else {
+				temporary = append(temporary, item)
+			}
+		}
+		var doc yaml.MapSlice
+		if len(permanent) > 0 {
+			doc = append(doc, yaml.MapItem{Key: 301, Value: permanent})
+		}
+		if len(temporary) > 0 {
+			doc = append(doc, yaml.MapItem{Key: 302, Value: temporary})
+		}
+		if len(doc) == 0 {
+			return []byte("{}\n"), nil
+		}
+		return yaml.Marshal(doc)
+	}
+	return nil, fmt.Errorf("unknown redirects format %q", format)
+}
+
+// UnmarshalRedirects decodes redirects in format. YAML redirects are read as
+// Ghost reads them: the temporary redirects under 302 first, then the
+// permanent ones under 301, each in file order.
+func UnmarshalRedirects(b []byte, format RedirectFormat) ([]*Redirect, error) {
+	switch format {
+	case RedirectsJSON:
+		var redirects []*Redirect
+		if err := json.Unmarshal(b, &redirects); err != nil {
+			return nil, err
+		}
+		return redirects, nil
+	case RedirectsYAML:
+		var doc yaml.MapSlice
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		groups := make(map[string]yaml.MapSlice)
+		for _, item := range doc {
+			group, ok := item.Value.(yaml.MapSlice)
+			key := fmt.Sprint(item.Key)
+			if !ok || key != "301" && key != "302" {
+				return nil, fmt.Errorf("invalid redirects YAML: unexpected key %v", item.Key)
+			}
+			groups[key] = group
+		}
+
+		redirects := []*Redirect{}
+		for _, key := range []string{"302", "301"} {
+			for _, item := range groups[key] {
+				from, ok := item.Key.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid redirects YAML: %s: from %v is not a string", key, item.Key)
+				}
+				to, ok := item.Value.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid redirects YAML: %s: target of %q is not a string", key, from)
+				}
+				redirects = append(redirects, &Redirect{From: from, To: to, Permanent: key == "301"})
+			}
+		}
+		return redirects, nil
+	}
+	return nil, fmt.Errorf("unknown redirects format %q", format)
+}
+
+// DetectRedirectFormat guesses the format of the redirects file b.
+func DetectRedirectFormat(b []byte) RedirectFormat {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		return RedirectsJSON
+	}
+	return RedirectsYAML
+}
+
+// ConvertRedirects converts the redirects file b, in either format, to
+// format.
+func ConvertRedirects(b []byte, format RedirectFormat) ([]byte, error) {
+	redirects, err := UnmarshalRedirects(b, DetectRedirectFormat(b))
+	if err != nil {
+		return nil, err
+	}
+	return MarshalRedirects(redirects, format)
+}
+
+// DownloadFile fetches the redirects file of Ghost 4 and later, in whichever
+// format it was uploaded, returning the redirects along with the format.
+func (s *AdminRedirectsService) DownloadFile() ([]*Redirect, RedirectFormat, *Response, error) {
+	buf := &bytes.Buffer{}
+	resp, err := s.downloadFile("redirects/download/", buf)
+	if err != nil {
+		return nil, "", resp, err
+	}
+
+	format := DetectRedirectFormat(buf.Bytes())
+	redirects, err := UnmarshalRedirects(buf.Bytes(), format)
+	if err != nil {
+		return nil, "", resp, err
+	}
+	return redirects, format, resp, nil
+}
+
+// DownloadRaw writes the redirects file, as is, to w, returning its format.
+// Ghost 3, which serves the redirects as JSON only, is fallen back to.
+func (s *AdminRedirectsService) DownloadRaw(w io.Writer) (RedirectFormat, *Response, error) {
+	buf := &bytes.Buffer{}
+	resp, err := s.downloadFile("redirects/download/", buf)
+	if isNotFound(err) {
+		buf.Reset()
+		resp, err = s.downloadFile("redirects/json", buf)
+	}
+	if err != nil {
+		return "", resp, err
+	}
+
+	format := DetectRedirectFormat(buf.Bytes())
+	if _, err := buf.WriteTo(w); err != nil {
+		return "", resp, err
+	}
+	return format, resp, nil
+}
+
+func (s *AdminRedirectsService) downloadFile(path string, w io.Writer) (*Response, error) {
+	req, err := s.client.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req, w)
+	return newResponse(resp), err
+}
+
+// UploadFile uploads the redirects as a file in format to Ghost 4 and later,
+// which keeps serving the redirects in that format. Redirects are linted as
+// by Upload.
+func (s *AdminRedirectsService) UploadFile(redirects []*Redirect, format RedirectFormat) (*Response, error) {
+	if err := s.lint(redirects); err != nil {
+		return nil, err
+	}
+
+	b, err := MarshalRedirects(redirects, format)
+	if err != nil {
+		return nil, err
+	}
+	return s.uploadFile("redirects/upload/", b, format)
+}
+
+// UploadRaw uploads r as the redirects file in format, as is. The redirects
+// are not linted, e.g. so that a backup can always be restored. Ghost 3,
+// which takes the redirects as JSON only, is fallen back to for JSON files.
+func (s *AdminRedirectsService) UploadRaw(r io.Reader, format RedirectFormat) (*Response, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.uploadFile("redirects/upload/", b, format)
+	if format == RedirectsJSON && isNotFound(err) {
+		return s.uploadFile("redirects/json", b, format)
+	}
+	return resp, err
+}
+
+func (s *AdminRedirectsService) uploadFile(path string, b []byte, format RedirectFormat) (*Response, error) {
+	contentType := "application/json"
+	if format == RedirectsYAML {
+		contentType = "application/yaml"
+	}
+	redirectsWriter := func(mpw *multipart.Writer) error {
+		part, err := createFormFile(mpw, "redirects", "redirects."+string(format), contentType)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(b)
+		return err
+	}
+
+	req, err := s.client.NewUploadRequest(path, redirectsWriter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req, nil)
+	return newResponse(resp), err
+}
+
+// isNotFound reports whether err is a 404 response, as from endpoints the
+// version of Ghost lacks.
+func isNotFound(err error) bool {
+	errResp, ok := err.(*ErrorResponse)
+	return ok && errResp.Response.StatusCode == http.StatusNotFound
+}
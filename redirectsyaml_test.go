@@ -0,0 +1,173 @@
+package ghost
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectsYAML = `301:
+  /old/: /new/
+  ^/blog/(.*): /$1
+302:
+  /temp/: /other/
+`
+
+func TestRedirects_YAML(t *testing.T) {
+	redirects, err := UnmarshalRedirects([]byte(testRedirectsYAML), RedirectsYAML)
+	require.NoError(t, err)
+	require.Equal(t, []*Redirect{
+		{From: "/temp/", To: "/other/"},
+		{From: "/old/", To: "/new/", Permanent: true},
+		{From: "^/blog/(.*)", To: "/$1", Permanent: true},
+	}, redirects)
+
+	b, err := MarshalRedirects(redirects, RedirectsYAML)
+	require.NoError(t, err)
+	require.Equal(t, testRedirectsYAML, string(b))
+
+	b, err = ConvertRedirects([]byte(testRedirectsYAML), RedirectsJSON)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"from": "/temp/", "to": "/other/"},
+		{"from": "/old/", "to": "/new/", "permanent": true},
+		{"from": "^/blog/(.*)", "to": "/$1", "permanent": true}
+	]`, string(b))
+	require.Equal(t, RedirectsJSON, DetectRedirectFormat(b))
+
+	_, err = UnmarshalRedirects([]byte("303:\n  /a/: /b/\n"), RedirectsYAML)
+	require.Error(t, err)
+
+	// targets and froms must be strings, rather than be stringified.
+	for _, doc := range []string{"301:\n  /a/: \n", "301:\n  /a/: 5\n", "301:\n  /a/: {b: c}\n"} {
+		_, err = UnmarshalRedirects([]byte(doc), RedirectsYAML)
+		require.EqualError(t, err, `invalid redirects YAML: 301: target of "/a/" is not a string`, doc)
+	}
+	_, err = UnmarshalRedirects([]byte("302:\n  404: /b/\n"), RedirectsYAML)
+	require.EqualError(t, err, "invalid redirects YAML: 302: from 404 is not a string")
+
+	// the same From may be both permanent and temporary, but only once each.
+	_, err = MarshalRedirects([]*Redirect{{From: "/a/", To: "/b/"}, {From: "/a/", To: "/c/", Permanent: true}}, RedirectsYAML)
+	require.NoError(t, err)
+	_, err = MarshalRedirects([]*Redirect{{From: "/a/", To: "/b/"}, {From: "/a/", To: "/c/"}}, RedirectsYAML)
+	require.Error(t, err)
+}
+
+func TestRedirectsService_PermanentRoundTrip(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	var uploaded string
+	mux.HandleFunc(BaseAdminPath+"redirects/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `[{"from": "/a/", "to": "/b/", "permanent": true}]`)
+			return
+		}
+		file, _, err := r.FormFile("redirects")
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		uploaded = string(b)
+	})
+
+	redirects, _, err := client.Redirects.Download()
+	require.NoError(t, err)
+	require.True(t, redirects[0].Permanent)
+
+	_, err = client.Redirects.Upload(redirects)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"from": "/a/", "to": "/b/", "permanent": true}]`, uploaded)
+}
+
+func TestRedirectsService_Files(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"redirects/download/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, testRedirectsYAML)
+	})
+	var filename, uploaded string
+	mux.HandleFunc(BaseAdminPath+"redirects/upload/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		file, header, err := r.FormFile("redirects")
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		filename, uploaded = header.Filename, string(b)
+	})
+
+	redirects, format, _, err := client.Redirects.DownloadFile()
+	require.NoError(t, err)
+	require.Equal(t, RedirectsYAML, format)
+	require.Len(t, redirects, 3)
+
+	_, err = client.Redirects.UploadFile(redirects, RedirectsYAML)
+	require.NoError(t, err)
+	require.Equal(t, "redirects.yaml", filename)
+	require.Equal(t, testRedirectsYAML, uploaded)
+}
+
+func TestRedirectsService_Raw(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	// the file is passed through as is, and not linted.
+	const file = "302:\n  ^/a: /a/\n"
+	mux.HandleFunc(BaseAdminPath+"redirects/download/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, file)
+	})
+	var uploaded string
+	mux.HandleFunc(BaseAdminPath+"redirects/upload/", func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("redirects")
+		require.NoError(t, err)
+		b, _ := ioutil.ReadAll(f)
+		uploaded = string(b)
+	})
+	client.redirectLint = &LintOptions{}
+
+	buf := &bytes.Buffer{}
+	format, _, err := client.Redirects.DownloadRaw(buf)
+	require.NoError(t, err)
+	require.Equal(t, RedirectsYAML, format)
+	require.Equal(t, file, buf.String())
+
+	_, err = client.Redirects.UploadRaw(buf, format)
+	require.NoError(t, err)
+	require.Equal(t, file, uploaded)
+}
+
+func TestRedirectsService_RawLegacy(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	// Ghost 3 has only the JSON endpoint.
+	var uploaded string
+	mux.HandleFunc(BaseAdminPath+"redirects/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `[{"from": "/a", "to": "/b"}]`)
+			return
+		}
+		f, _, err := r.FormFile("redirects")
+		require.NoError(t, err)
+		b, _ := ioutil.ReadAll(f)
+		uploaded = string(b)
+	})
+
+	buf := &bytes.Buffer{}
+	format, _, err := client.Redirects.DownloadRaw(buf)
+	require.NoError(t, err)
+	require.Equal(t, RedirectsJSON, format)
+
+	_, err = client.Redirects.UploadRaw(buf, format)
+	require.NoError(t, err)
+	require.Equal(t, `[{"from": "/a", "to": "/b"}]`, uploaded)
+
+	_, err = client.Redirects.UploadRaw(strings.NewReader("{}\n"), RedirectsYAML)
+	require.Error(t, err)
+}
@@ -0,0 +1,216 @@
+package ghost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+	"golang.org/x/oauth2"
+)
+
+// SiteConfig describes how to reach and authenticate with a single Ghost site.
+// Either AdminKey or Username and Password must be set.
+type SiteConfig struct {
+	Name     string `json:"name"`
+	BaseURL  string `json:"base_url"`
+	AdminKey string `json:"admin_key,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// RegistryConfig is the structure of a registry config file.
+type RegistryConfig struct {
+	Sites []*SiteConfig `json:"sites"`
+}
+
+// A Registry manages AdminClients for a fleet of Ghost sites. Clients are
+// built and authenticated lazily, the first time they are requested.
+type Registry struct {
+	opts  []AdminClientOption
+	names []string
+	sites map[string]*registrySite
+}
+
+type registrySite struct {
+	config *SiteConfig
+
+	mu     sync.Mutex
+	client *AdminClient
+}
+
+// SiteFunc is an operation run against a single site of the Registry.
+type SiteFunc func(ctx context.Context, site string, client *AdminClient) error
+
+// SiteErrors aggregates the errors of an operation run across many sites,
+// keyed by site name.
+type SiteErrors map[string]error
+
+func (e SiteErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("%s: %v", name, e[name])
+	}
+	return fmt.Sprintf("%d site(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// NewRegistry returns a Registry for the provided sites. Site names must be
+// unique. Each site's AdminClient is constructed with opts.
+func NewRegistry(sites []*SiteConfig, opts ...AdminClientOption) (*Registry, error) {
+	r := &Registry{opts: opts, sites: make(map[string]*registrySite, len(sites))}
+	for _, site := range sites {
+		if err := validateSiteConfig(site); err != nil {
+			return nil, err
+		}
+		if _, ok := r.sites[site.Name]; ok {
+			return nil, fmt.Errorf("duplicate site %q", site.Name)
+		}
+		r.names = append(r.names, site.Name)
+		r.sites[site.Name] = &registrySite{config: site}
+	}
+	sort.Strings(r.names)
+	return r, nil
+}
+
+// LoadRegistry reads a JSON RegistryConfig from the file at path and returns
+// the Registry for it.
+func LoadRegistry(path string, opts ...AdminClientOption) (*Registry, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := new(RegistryConfig)
+	if err := json.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("failed to parse registry config %s: %v", path, err)
+	}
+	return NewRegistry(config.Sites, opts...)
+}
+
+func validateSiteConfig(site *SiteConfig) error {
+	if site == nil || site.Name == "" {
+		return fmt.Errorf("site must have a name")
+	}
+	if site.BaseURL == "" {
+		return fmt.Errorf("site %q must have a base url", site.Name)
+	}
+	if site.AdminKey == "" && (site.Username == "" || site.Password == "") {
+		return fmt.Errorf("site %q must have an admin key or session credentials", site.Name)
+	}
+	return nil
+}
+
+// Sites returns the names of all sites in the registry, sorted.
+func (r *Registry) Sites() []string {
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+	return names
+}
+
+// Client returns the authenticated AdminClient of the named site, building
+// it on first use. Failed attempts are not cached, so a later call retries.
+func (r *Registry) Client(name string) (*AdminClient, error) {
+	site, ok := r.sites[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown site %q", name)
+	}
+
+	site.mu.Lock()
+	defer site.mu.Unlock()
+	if site.client != nil {
+		return site.client, nil
+	}
+
+	client, err := newSiteClient(site.config, r.opts)
+	if err != nil {
+		return nil, err
+	}
+	site.client = client
+	return client, nil
+}
+
+func newSiteClient(config *SiteConfig, opts []AdminClientOption) (*AdminClient, error) {
+	if config.AdminKey != "" {
+		ts, err := NewAdminTokenSource(config.AdminKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewAdminClient(config.BaseURL, oauth2.NewClient(context.Background(), ts), opts...)
+	}
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewAdminClient(config.BaseURL, &http.Client{Jar: jar}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.Session.Create(config.Username, config.Password); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// ForEach runs fn against every site of the registry concurrently, with at
+// most parallelism sites in flight at once. Errors, including failures to
+// build a site's client, are collected into SiteErrors. Sites not yet started
+// when ctx is done are reported with the context's error.
+func (r *Registry) ForEach(ctx context.Context, parallelism int, fn SiteFunc) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		mu   sync.Mutex
+		errs = make(SiteErrors)
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, parallelism)
+	)
+	record := func(name string, err error) {
+		mu.Lock()
+		errs[name] = err
+		mu.Unlock()
+	}
+
+	for _, name := range r.names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			record(name, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			client, err := r.Client(name)
+			if err == nil {
+				err = fn(ctx, name, client)
+			}
+			if err != nil {
+				record(name, err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package ghost
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry_invalid(t *testing.T) {
+	_, err := NewRegistry([]*SiteConfig{{Name: "a", BaseURL: "https://a.pubbit.io"}})
+	require.Error(t, err)
+
+	_, err = NewRegistry([]*SiteConfig{
+		{Name: "a", BaseURL: "https://a.pubbit.io", AdminKey: ExampleAdminKey},
+		{Name: "a", BaseURL: "https://b.pubbit.io", AdminKey: ExampleAdminKey},
+	})
+	require.Error(t, err)
+}
+
+func TestLoadRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sites.json")
+	config := fmt.Sprintf(`{"sites": [
+		{"name": "b", "base_url": "https://b.pubbit.io", "username": "u", "password": "p"},
+		{"name": "a", "base_url": "https://a.pubbit.io", "admin_key": %q}
+	]}`, ExampleAdminKey)
+	require.NoError(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+	r, err := LoadRegistry(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, r.Sites())
+
+	c, err := r.Client("a")
+	require.NoError(t, err)
+	again, err := r.Client("a")
+	require.NoError(t, err)
+	require.True(t, c == again)
+
+	_, err = r.Client("missing")
+	require.Error(t, err)
+}
+
+func TestRegistry_ForEach(t *testing.T) {
+	var sessions int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(BaseAdminPath+"session/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sessions, 1)
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	r, err := NewRegistry([]*SiteConfig{
+		{Name: "one", BaseURL: server.URL, Username: "u", Password: "p"},
+		{Name: "two", BaseURL: server.URL, AdminKey: ExampleAdminKey},
+		{Name: "three", BaseURL: server.URL, AdminKey: ExampleAdminKey},
+	})
+	require.NoError(t, err)
+
+	var visited int32
+	err = r.ForEach(context.Background(), 2, func(ctx context.Context, site string, client *AdminClient) error {
+		atomic.AddInt32(&visited, 1)
+		if strings.HasPrefix(site, "t") {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	})
+	require.Equal(t, int32(3), visited)
+	require.Equal(t, int32(1), sessions)
+
+	siteErrs, ok := err.(SiteErrors)
+	require.True(t, ok)
+	require.Len(t, siteErrs, 2)
+	require.Contains(t, siteErrs, "two")
+	require.Contains(t, siteErrs, "three")
+	require.Equal(t, "2 site(s) failed: three: boom; two: boom", err.Error())
+}
+
+func TestRegistry_ForEach_canceled(t *testing.T) {
+	r, err := NewRegistry([]*SiteConfig{
+		{Name: "a", BaseURL: "https://a.pubbit.io", AdminKey: ExampleAdminKey},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = r.ForEach(ctx, 1, func(ctx context.Context, site string, client *AdminClient) error {
+		return ctx.Err()
+	})
+	require.Equal(t, SiteErrors{"a": context.Canceled}, err)
+}
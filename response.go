@@ -0,0 +1,44 @@
+package ghost
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response wraps the http.Response of an API call, exposing Ghost specific
+// metadata alongside the raw response.
+type Response struct {
+	*http.Response
+
+	// ContentVersion is the Ghost version serving the request, taken from the
+	// Content-Version header, e.g. "v3.15".
+	ContentVersion string
+
+	// RetryAfter is how long Ghost asks clients to back off for, as sent
+	// alongside 429 responses. It is zero when not present.
+	RetryAfter time.Duration
+
+	// Meta is the pagination info of list responses, nil otherwise.
+	Meta *Meta
+}
+
+func newResponse(r *http.Response) *Response {
+	if r == nil {
+		return nil
+	}
+
+	response := &Response{
+		Response:       r,
+		ContentVersion: r.Header.Get("Content-Version"),
+	}
+	if secs, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+		response.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return response
+}
+
+// CacheControl returns the Cache-Control header of the response.
+func (r *Response) CacheControl() string {
+	return r.Header.Get("Cache-Control")
+}
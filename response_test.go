@@ -0,0 +1,27 @@
+package ghost
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_errorStatus(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"redirects/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("Content-Version", "v3.15")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, resp, err := client.Redirects.Download()
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, 30*time.Second, resp.RetryAfter)
+	require.Equal(t, "v3.15", resp.ContentVersion)
+}
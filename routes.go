@@ -0,0 +1,338 @@
+package ghost
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// AdminRoutesService handles downloading and uploading the routes.yaml file,
+// which configures Ghost's dynamic routing.
+type AdminRoutesService adminService
+
+// Templates are the templates of a route, in order of preference. In
+// routes.yaml they are either a single name or a list.
+type Templates []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *Templates) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*t = Templates{name}
+		return nil
+	}
+	var names []string
+	if err := unmarshal(&names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (t Templates) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
+}
+
+// RouteOptions are the options shared by routes and collections. Data is
+// either the short form such as "tag.news" or a map of named resources.
+type RouteOptions struct {
+	Template Templates   `yaml:"template,omitempty"`
+	Data     interface{} `yaml:"data,omitempty"`
+	Filter   string      `yaml:"filter,omitempty"`
+	Order    string      `yaml:"order,omitempty"`
+	Limit    int         `yaml:"limit,omitempty"`
+	RSS      *bool       `yaml:"rss,omitempty"`
+}
+
+// Route is a custom route, rendering Template at Path, or a list of posts
+// when Controller is "channel".
+type Route struct {
+	Path         string `yaml:"-"`
+	Controller   string `yaml:"controller,omitempty"`
+	ContentType  string `yaml:"content_type,omitempty"`
+	RouteOptions `yaml:",inline"`
+}
+
+// Collection is a collection of posts listed at Path, with the posts
+// themselves served at Permalink.
+type Collection struct {
+	Path         string `yaml:"-"`
+	Permalink    string `yaml:"permalink"`
+	RouteOptions `yaml:",inline"`
+}
+
+// Taxonomies are the permalinks of tag and author archives.
+type Taxonomies struct {
+	Tag    string `yaml:"tag,omitempty"`
+	Author string `yaml:"author,omitempty"`
+}
+
+// RoutesSettings is the content of routes.yaml. Routes and collections are
+// matched in order.
+type RoutesSettings struct {
+	Routes      []*Route
+	Collections []*Collection
+	Taxonomies  *Taxonomies
+}
+
+func (rs RoutesSettings) String() string {
+	b, _ := yaml.Marshal(rs)
+	return string(b)
+}
+
+type routesFile struct {
+	Routes      yaml.MapSlice `yaml:"routes"`
+	Collections yaml.MapSlice `yaml:"collections"`
+	Taxonomies  *Taxonomies   `yaml:"taxonomies"`
+}
+
+// remarshal decodes the generic value v into out.
+func remarshal(v interface{}, out interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, out)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (rs *RoutesSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var file routesFile
+	if err := unmarshal(&file); err != nil {
+		return err
+	}
+
+	*rs = RoutesSettings{Taxonomies: file.Taxonomies}
+	for _, item := range file.Routes {
+		route := &Route{}
+		switch value := item.Value.(type) {
+		case nil:
+		case string:
+			// the short form names just the template.
+			route.Template = Templates{value}
+		default:
+			if err := remarshal(value, route); err != nil {
+				return fmt.Errorf("route %v: %v", item.Key, err)
+			}
+		}
+		route.Path = fmt.Sprint(item.Key)
+		rs.Routes = append(rs.Routes, route)
+	}
+	for _, item := range file.Collections {
+		collection := &Collection{}
+		if err := remarshal(item.Value, collection); err != nil {
+			return fmt.Errorf("collection %v: %v", item.Key, err)
+		}
+		collection.Path = fmt.Sprint(item.Key)
+		rs.Collections = append(rs.Collections, collection)
+	}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (rs RoutesSettings) MarshalYAML() (interface{}, error) {
+	file := routesFile{Routes: yaml.MapSlice{}, Collections: yaml.MapSlice{}, Taxonomies: rs.Taxonomies}
+	for _, route := range rs.Routes {
+		var value interface{} = route
+		if len(route.Template) == 1 && route.Controller == "" && route.ContentType == "" &&
+			route.Data == nil && route.Filter == "" && route.Order == "" && route.Limit == 0 && route.RSS == nil {
+			value = route.Template[0]
+		}
+		file.Routes = append(file.Routes, yaml.MapItem{Key: route.Path, Value: value})
+	}
+	for _, collection := range rs.Collections {
+		file.Collections = append(file.Collections, yaml.MapItem{Key: collection.Path, Value: collection})
+	}
+	if file.Taxonomies == nil {
+		file.Taxonomies = &Taxonomies{}
+	}
+	return file, nil
+}
+
+// RoutesError is a problem with routes.yaml, at Key.
+type RoutesError struct {
+	Key     string
+	Message string
+}
+
+func (e *RoutesError) Error() string {
+	return fmt.Sprintf("routes.yaml %s: %s", e.Key, e.Message)
+}
+
+// permalinkParams are the placeholders Ghost supports in permalinks.
+var permalinkParams = map[string]bool{
+	"id": true, "uuid": true, "slug": true, "year": true, "month": true, "day": true,
+	"primary_tag": true, "primary_author": true,
+}
+
+var (
+	permalinkParam = regexp.MustCompile(`\{([^{}]*)\}`)
+	shortData      = regexp.MustCompile(`^(post|page|tag|author)\.[^.\s]+$`)
+)
+
+// validatePermalink checks a permalink template like /{year}/{slug}/, which
+// must contain identifying as one of placeholders.
+func validatePermalink(key, permalink string, identifying ...string) error {
+	if permalink == "" {
+		return &RoutesError{key, "a permalink is required, e.g. /{slug}/"}
+	}
+	if !strings.HasPrefix(permalink, "/") || !strings.HasSuffix(permalink, "/") {
+		return &RoutesError{key, fmt.Sprintf("permalink %q needs a leading and trailing slash", permalink)}
+	}
+	if strings.Count(permalink, "{") != strings.Count(permalink, "}") {
+		return &RoutesError{key, fmt.Sprintf("permalink %q has unbalanced braces", permalink)}
+	}
+
+	identified := false
+	for _, match := range permalinkParam.FindAllStringSubmatch(permalink, -1) {
+		if !permalinkParams[match[1]] {
+			return &RoutesError{key, fmt.Sprintf("permalink %q has unknown placeholder {%s}", permalink, match[1])}
+		}
+		for _, param := range identifying {
+			identified = identified || match[1] == param
+		}
+	}
+	if !identified {
+		return &RoutesError{key, fmt.Sprintf("permalink %q must contain one of {%s}", permalink, strings.Join(identifying, "}, {"))}
+	}
+	return nil
+}
+
+func validateRoutePath(key, path string) error {
+	if !strings.HasPrefix(path, "/") || !strings.HasSuffix(path, "/") {
+		return &RoutesError{key, "a leading and trailing slash is required"}
+	}
+	return nil
+}
+
+func validateData(key string, data interface{}) error {
+	if short, ok := data.(string); ok && !shortData.MatchString(short) {
+		return &RoutesError{key, fmt.Sprintf("data %q must take the form resource.slug, e.g. tag.news", short)}
+	}
+	return nil
+}
+
+// Validate checks the routes as Ghost does on upload, returning a
+// *RoutesError for the first problem: paths need leading and trailing
+// slashes, collection permalinks must only use known placeholders and contain
+// {id}, {uuid} or {slug}, taxonomy permalinks must contain {slug}, and data
+// in the short form must name a post, page, tag or author.
+func (rs *RoutesSettings) Validate() error {
+	for _, route := range rs.Routes {
+		key := "routes." + route.Path
+		if err := validateRoutePath(key, route.Path); err != nil {
+			return err
+		}
+		if route.Controller != "" && route.Controller != "channel" {
+			return &RoutesError{key, fmt.Sprintf("unknown controller %q", route.Controller)}
+		}
+		if err := validateData(key, route.Data); err != nil {
+			return err
+		}
+	}
+
+	for _, collection := range rs.Collections {
+		key := "collections." + collection.Path
+		if err := validateRoutePath(key, collection.Path); err != nil {
+			return err
+		}
+		if err := validatePermalink(key, collection.Permalink, "id", "uuid", "slug"); err != nil {
+			return err
+		}
+		if err := validateData(key, collection.Data); err != nil {
+			return err
+		}
+	}
+
+	if rs.Taxonomies != nil {
+		if rs.Taxonomies.Tag != "" {
+			if err := validatePermalink("taxonomies.tag", rs.Taxonomies.Tag, "slug"); err != nil {
+				return err
+			}
+		}
+		if rs.Taxonomies.Author != "" {
+			if err := validatePermalink("taxonomies.author", rs.Taxonomies.Author, "slug"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseRoutes parses the content of a routes.yaml file.
+func ParseRoutes(b []byte) (*RoutesSettings, error) {
+	rs := &RoutesSettings{}
+	if err := yaml.Unmarshal(b, rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// DownloadFile writes the routes.yaml file, as is, to w.
+func (s *AdminRoutesService) DownloadFile(w io.Writer) (*Response, error) {
+	req, err := s.client.NewRequest("GET", "settings/routes/yaml", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req, w)
+	return newResponse(resp), err
+}
+
+// Download fetches and parses the routes.yaml file.
+func (s *AdminRoutesService) Download() (*RoutesSettings, *Response, error) {
+	buf := &bytes.Buffer{}
+	resp, err := s.DownloadFile(buf)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	rs, err := ParseRoutes(buf.Bytes())
+	if err != nil {
+		return nil, resp, err
+	}
+	return rs, resp, nil
+}
+
+// UploadFile uploads r as the routes.yaml file, as is.
+func (s *AdminRoutesService) UploadFile(r io.Reader) (*Response, error) {
+	routesWriter := func(mpw *multipart.Writer) error {
+		part, err := createFormFile(mpw, "routes", "routes.yaml", "application/x-yaml")
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, r)
+		return err
+	}
+
+	req, err := s.client.NewStreamingUploadRequest("settings/routes/yaml", routesWriter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req, nil)
+	return newResponse(resp), err
+}
+
+// Upload validates and uploads the routes. Comments and formatting of the
+// previous routes.yaml are not kept.
+func (s *AdminRoutesService) Upload(rs *RoutesSettings) (*Response, error) {
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := yaml.Marshal(rs)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadFile(bytes.NewReader(b))
+}
@@ -0,0 +1,114 @@
+package ghost
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRoutesYAML = `routes:
+  /about/team/: team
+  /podcast/rss/:
+    content_type: text/xml
+    template: podcast/rss
+  /features/:
+    controller: channel
+    data: tag.features
+    filter: tag:features
+collections:
+  /blog/:
+    permalink: /blog/{year}/{slug}/
+    template:
+    - blog
+    - index
+    filter: primary_tag:blog
+  /:
+    permalink: /{slug}/
+    template: index
+taxonomies:
+  tag: /tag/{slug}/
+  author: /author/{slug}/
+`
+
+func TestRoutes_ParseRoundTrip(t *testing.T) {
+	rs, err := ParseRoutes([]byte(testRoutesYAML))
+	require.NoError(t, err)
+	require.NoError(t, rs.Validate())
+
+	require.Len(t, rs.Routes, 3)
+	require.Equal(t, "/about/team/", rs.Routes[0].Path)
+	require.Equal(t, Templates{"team"}, rs.Routes[0].Template)
+	require.Equal(t, "text/xml", rs.Routes[1].ContentType)
+	require.Equal(t, "channel", rs.Routes[2].Controller)
+	require.Equal(t, "tag.features", rs.Routes[2].Data)
+
+	require.Len(t, rs.Collections, 2)
+	require.Equal(t, "/blog/", rs.Collections[0].Path)
+	require.Equal(t, Templates{"blog", "index"}, rs.Collections[0].Template)
+	require.Equal(t, "primary_tag:blog", rs.Collections[0].Filter)
+	require.Equal(t, &Taxonomies{Tag: "/tag/{slug}/", Author: "/author/{slug}/"}, rs.Taxonomies)
+
+	require.Equal(t, testRoutesYAML, rs.String())
+}
+
+func TestRoutes_Validate(t *testing.T) {
+	tests := []struct {
+		yaml string
+		key  string
+	}{
+		{"routes:\n  /about: about\n", "routes./about"},
+		{"routes:\n  /x/:\n    controller: list\n", "routes./x/"},
+		{"routes:\n  /x/:\n    data: features\n", "routes./x/"},
+		{"collections:\n  /:\n    template: index\n", "collections./"},
+		{"collections:\n  /:\n    permalink: /{year}/\n", "collections./"},
+		{"collections:\n  /:\n    permalink: /{title}/\n", "collections./"},
+		{"collections:\n  /:\n    permalink: /{slug}\n", "collections./"},
+		{"taxonomies:\n  tag: /tag/{id}/\n", "taxonomies.tag"},
+	}
+	for _, test := range tests {
+		rs, err := ParseRoutes([]byte(test.yaml))
+		require.NoError(t, err, test.yaml)
+		err = rs.Validate()
+		routesErr, ok := err.(*RoutesError)
+		require.True(t, ok, "%s: %v", test.yaml, err)
+		require.Equal(t, test.key, routesErr.Key, test.yaml)
+	}
+}
+
+func TestRoutesService_DownloadUpload(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	var filename, contentType, uploaded string
+	mux.HandleFunc(BaseAdminPath+"settings/routes/yaml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, testRoutesYAML)
+			return
+		}
+		testMethod(t, r, "POST")
+		file, header, err := r.FormFile("routes")
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		filename, contentType, uploaded = header.Filename, header.Header.Get("Content-Type"), string(b)
+	})
+
+	rs, _, err := client.Routes.Download()
+	require.NoError(t, err)
+	require.Len(t, rs.Collections, 2)
+
+	_, err = client.Routes.Upload(rs)
+	require.NoError(t, err)
+	require.Equal(t, "routes.yaml", filename)
+	require.Equal(t, "application/x-yaml", contentType)
+	require.Equal(t, testRoutesYAML, uploaded)
+
+	uploaded = ""
+	rs.Collections[0].Permalink = "/blog/{year}/"
+	_, err = client.Routes.Upload(rs)
+	require.Error(t, err)
+	require.Empty(t, uploaded)
+}
@@ -0,0 +1,315 @@
+// Package scrub removes personal data and secrets from Ghost database exports,
+// so production content can be imported into staging environments. Scrubbed
+// exports keep their referential integrity and still import cleanly.
+package scrub
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// Rules configure what is scrubbed.
+type Rules struct {
+	// HashEmails replaces every email address, of staff users, members and
+	// any other column named email or ending in _email, with a hash of it at
+	// EmailDomain. The same address always hashes to the same replacement,
+	// so uniqueness and cross-table matches are kept.
+	HashEmails bool
+	// EmailDomain is the domain of hashed email addresses. Defaults to
+	// example.com.
+	EmailDomain string
+	// Salt keys the email hash, so that hashes cannot be reversed by hashing
+	// guessed addresses.
+	Salt string
+
+	// DropTables are removed from the export entirely, along with the tables
+	// whose rows reference them. Core content tables cannot be dropped.
+	DropTables []string
+
+	// ResetPasswords replaces the password hashes of staff users with
+	// PasswordHash, or with an unusable random value if PasswordHash is
+	// empty, requiring a password reset before logging in.
+	ResetPasswords bool
+	// PasswordHash is the bcrypt hash given to every staff user when
+	// ResetPasswords is set.
+	PasswordHash string
+
+	// BlankSettings are the keys of settings whose values are blanked. For
+	// settings holding JSON, only nested values under names that look like
+	// secrets (containing key, secret or token) are blanked.
+	BlankSettings []string
+}
+
+// DefaultRules hash emails, drop sessions, api keys and other credentials,
+// reset staff passwords and blank payment and email provider settings.
+func DefaultRules() *Rules {
+	return &Rules{
+		HashEmails:     true,
+		DropTables:     []string{"sessions", "api_keys", "tokens", "brute", "invites", "members_stripe_customers"},
+		ResetPasswords: true,
+		BlankSettings: []string{
+			"stripe_secret_key",
+			"stripe_publishable_key",
+			"stripe_connect_secret_key",
+			"stripe_connect_publishable_key",
+			"stripe_connect_account_id",
+			"members_stripe_webhook_id",
+			"members_stripe_webhook_secret",
+			"members_subscription_settings",
+			"bulk_email_settings",
+			"mailgun_api_key",
+		},
+	}
+}
+
+// coreTables hold the content itself, so dropping them would leave nothing
+// worth importing.
+var coreTables = map[string]bool{
+	"posts": true, "tags": true, "users": true, "roles": true, "settings": true,
+}
+
+// dependentTables lists, per table, the tables whose rows reference it.
+var dependentTables = map[string][]string{
+	"members": {
+		"members_labels",
+		"members_stripe_customers",
+		"members_email_change_events",
+		"members_login_events",
+		"members_payment_events",
+		"members_status_events",
+		"members_subscribe_events",
+		"email_recipients",
+	},
+	"members_stripe_customers": {"members_stripe_customers_subscriptions"},
+	"labels":                   {"members_labels"},
+	"integrations":             {"api_keys", "webhooks"},
+	"emails":                   {"email_batches", "email_recipients"},
+	"email_batches":            {"email_recipients"},
+}
+
+// Apply scrubs db in place according to rules.
+func Apply(db *ghost.Database, rules *Rules) error {
+	if db.Data == nil {
+		return nil
+	}
+	s := &scrubber{rules: rules, domain: rules.EmailDomain}
+	if s.domain == "" {
+		s.domain = "example.com"
+	}
+
+	if err := s.dropTables(db.Data); err != nil {
+		return err
+	}
+	if rules.HashEmails {
+		if err := s.hashEmails(db.Data); err != nil {
+			return err
+		}
+	}
+	if rules.ResetPasswords {
+		if err := s.resetPasswords(db.Data); err != nil {
+			return err
+		}
+	}
+	return s.blankSettings(db.Data)
+}
+
+type scrubber struct {
+	rules  *Rules
+	domain string
+}
+
+func (s *scrubber) dropTables(data *ghost.DatabaseData) error {
+	drop := make(map[string]bool)
+	var visit func(table string)
+	visit = func(table string) {
+		if drop[table] {
+			return
+		}
+		drop[table] = true
+		for _, dependent := range dependentTables[table] {
+			visit(dependent)
+		}
+	}
+	for _, table := range s.rules.DropTables {
+		if coreTables[table] {
+			return fmt.Errorf("cannot drop core table %q", table)
+		}
+		visit(table)
+	}
+
+	for table := range drop {
+		switch table {
+		case "posts_meta":
+			data.PostsMeta = nil
+		case "posts_tags":
+			data.PostsTags = nil
+		case "posts_authors":
+			data.PostsAuthors = nil
+		case "roles_users":
+			data.RolesUsers = nil
+		case "members":
+			data.Members = nil
+		}
+		delete(data.Extra, table)
+	}
+	return nil
+}
+
+// hashEmail deterministically replaces address.
+func (s *scrubber) hashEmail(address string) string {
+	if address == "" {
+		return address
+	}
+	mac := hmac.New(sha256.New, []byte(s.rules.Salt))
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(address))))
+	return hex.EncodeToString(mac.Sum(nil))[:20] + "@" + s.domain
+}
+
+func (s *scrubber) hashEmailPtr(address *string) {
+	if address != nil {
+		*address = s.hashEmail(*address)
+	}
+}
+
+func isEmailColumn(name string) bool {
+	return name == "email" || strings.HasSuffix(name, "_email")
+}
+
+// hashColumns hashes the email columns among columns, reporting whether any
+// were changed.
+func (s *scrubber) hashColumns(columns ghost.Columns) (bool, error) {
+	changed := false
+	for name, value := range columns {
+		if !isEmailColumn(name) {
+			continue
+		}
+		var address *string
+		if err := json.Unmarshal(value, &address); err != nil || address == nil {
+			continue
+		}
+		b, err := json.Marshal(s.hashEmail(*address))
+		if err != nil {
+			return false, err
+		}
+		columns[name] = b
+		changed = true
+	}
+	return changed, nil
+}
+
+func (s *scrubber) hashEmails(data *ghost.DatabaseData) error {
+	for _, user := range data.Users {
+		s.hashEmailPtr(user.Email)
+		if _, err := s.hashColumns(user.Extra); err != nil {
+			return err
+		}
+	}
+	for _, member := range data.Members {
+		s.hashEmailPtr(member.Email)
+		if _, err := s.hashColumns(member.Extra); err != nil {
+			return err
+		}
+	}
+
+	// tables without typed rows are scrubbed generically.
+	for table, value := range data.Extra {
+		var rows []ghost.Columns
+		if err := json.Unmarshal(value, &rows); err != nil {
+			continue
+		}
+		changed := false
+		for _, row := range rows {
+			rowChanged, err := s.hashColumns(row)
+			if err != nil {
+				return err
+			}
+			changed = changed || rowChanged
+		}
+		if !changed {
+			continue
+		}
+		b, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		data.Extra[table] = b
+	}
+	return nil
+}
+
+func (s *scrubber) resetPasswords(data *ghost.DatabaseData) error {
+	for _, user := range data.Users {
+		password := s.rules.PasswordHash
+		if password == "" {
+			b := make([]byte, 25)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			password = hex.EncodeToString(b)
+		}
+		user.Password = ghost.String(password)
+	}
+	return nil
+}
+
+func (s *scrubber) blankSettings(data *ghost.DatabaseData) error {
+	blank := make(map[string]bool, len(s.rules.BlankSettings))
+	for _, key := range s.rules.BlankSettings {
+		blank[key] = true
+	}
+
+	for _, setting := range data.Settings {
+		if setting.Key == nil || !blank[*setting.Key] || setting.Value == nil {
+			continue
+		}
+
+		var nested interface{}
+		if err := json.Unmarshal([]byte(*setting.Value), &nested); err == nil {
+			if _, ok := nested.(map[string]interface{}); ok {
+				b, err := json.Marshal(blankSecrets(nested, false))
+				if err != nil {
+					return err
+				}
+				setting.Value = ghost.String(string(b))
+				continue
+			}
+		}
+		setting.Value = ghost.String("")
+	}
+	return nil
+}
+
+func looksSecret(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "key") || strings.Contains(name, "secret") || strings.Contains(name, "token")
+}
+
+// blankSecrets blanks the strings of v held under secret looking names, or
+// all strings once secret is set.
+func blankSecrets(v interface{}, secret bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			v[name] = blankSecrets(value, secret || looksSecret(name))
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = blankSecrets(value, secret)
+		}
+		return v
+	case string:
+		if secret {
+			return ""
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,69 @@
+package scrub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/stretchr/testify/require"
+)
+
+const testExport = `{"meta": {"version": "3.15.1"}, "data": {
+	"users": [{"id": "u1", "email": "Staff@Blog.com", "password": "$2a$10$hash"}],
+	"members": [{"id": "m1", "email": "reader@mail.com"}],
+	"members_labels": [{"id": "ml1", "member_id": "m1", "label_id": "l1"}],
+	"email_recipients": [{"id": "er1", "member_email": "reader@mail.com"}],
+	"sessions": [{"id": "s1", "session_data": "{}"}],
+	"api_keys": [{"id": "k1", "secret": "abc"}],
+	"settings": [
+		{"id": "1", "key": "title", "value": "Blog"},
+		{"id": "2", "key": "stripe_secret_key", "value": "sk_live_123"},
+		{"id": "3", "key": "members_subscription_settings", "value": "{\"allowSelfSignup\":true,\"paymentProcessors\":[{\"adapter\":\"stripe\",\"config\":{\"secret_token\":\"sk\",\"public_token\":\"pk\",\"product\":{\"name\":\"Blog\"}}}]}"}
+	]
+}}`
+
+func TestApply(t *testing.T) {
+	db := new(ghost.Database)
+	require.NoError(t, json.Unmarshal([]byte(testExport), db))
+
+	rules := DefaultRules()
+	rules.DropTables = append(rules.DropTables, "members")
+	rules.HashEmails = true
+	rules.Salt = "pepper"
+	require.NoError(t, Apply(db, rules))
+
+	user := db.Data.Users[0]
+	require.NotEqual(t, "$2a$10$hash", *user.Password)
+	require.Regexp(t, `^[0-9a-f]{20}@example\.com$`, *user.Email)
+
+	require.Nil(t, db.Data.Members)
+	require.NotContains(t, db.Data.Extra, "members_labels")
+	require.NotContains(t, db.Data.Extra, "email_recipients")
+	require.NotContains(t, db.Data.Extra, "sessions")
+	require.NotContains(t, db.Data.Extra, "api_keys")
+
+	require.Equal(t, "Blog", *db.Data.Settings[0].Value)
+	require.Equal(t, "", *db.Data.Settings[1].Value)
+	require.JSONEq(t,
+		`{"allowSelfSignup":true,"paymentProcessors":[{"adapter":"stripe","config":{"secret_token":"","public_token":"","product":{"name":"Blog"}}}]}`,
+		*db.Data.Settings[2].Value)
+
+	require.Empty(t, db.Validate(""))
+}
+
+func TestApply_consistentEmails(t *testing.T) {
+	db := new(ghost.Database)
+	require.NoError(t, json.Unmarshal([]byte(testExport), db))
+	require.NoError(t, Apply(db, &Rules{HashEmails: true, EmailDomain: "staging.test"}))
+
+	var recipients []ghost.Columns
+	require.NoError(t, json.Unmarshal(db.Data.Extra["email_recipients"], &recipients))
+	require.Equal(t, `"`+*db.Data.Members[0].Email+`"`, string(recipients[0]["member_email"]))
+	require.Contains(t, *db.Data.Members[0].Email, "@staging.test")
+	require.Equal(t, "$2a$10$hash", *db.Data.Users[0].Password)
+}
+
+func TestApply_coreTable(t *testing.T) {
+	db := &ghost.Database{Data: &ghost.DatabaseData{}}
+	require.Error(t, Apply(db, &Rules{DropTables: []string{"posts"}}))
+}
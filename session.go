@@ -17,15 +17,16 @@ type userCredentials struct {
 
 // Create creates the session. The cookie should be set in the underlying
 // http.Client cookiejar, allowing use of the session for the duration of the client.
-func (s *AdminSessionService) Create(username, password string) error {
+func (s *AdminSessionService) Create(username, password string) (*Response, error) {
 	creds := &userCredentials{
 		Username: username,
 		Password: password,
 	}
 	req, err := s.client.NewRequest("POST", "session/", creds)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	req = req.WithContext(withLoginBudget(req.Context()))
 
 	// we want to read the entire response stream or we may run into a
 	// race condition and have our next call hit 403 because the session token
@@ -34,15 +35,15 @@ func (s *AdminSessionService) Create(username, password string) error {
 	var body interface{}
 	response, err := s.client.Do(req, body)
 	if err != nil {
-		return err
+		return newResponse(response), err
 	}
 	if response.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to establish session")
+		return newResponse(response), fmt.Errorf("failed to establish session")
 	}
 
 	// in fact, even reading the entire result stream is sometimes insufficient
 	// so we will wait a couple milliseconds here as well. TOOD: fix upstream
 	time.Sleep(time.Millisecond * 10)
 
-	return nil
+	return newResponse(response), nil
 }
@@ -0,0 +1,16 @@
+package ghost
+
+// Site is the basic information of the site.
+type Site struct {
+	Title   *string `json:"title"`
+	URL     *string `json:"url"`
+	Version *string `json:"version"`
+}
+
+func (s Site) String() string {
+	return Stringify(s)
+}
+
+type siteWrapper struct {
+	Site *Site `json:"site"`
+}
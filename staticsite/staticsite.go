@@ -0,0 +1,407 @@
+// Package staticsite exports the posts of a Ghost site as the content of a
+// Hugo or Jekyll site, for archiving sites that are retired.
+//
+// Posts are written as HTML with YAML front matter, at their original
+// permalinks. Content images of the site are downloaded to the same paths
+// under /content/images/, and references to them and to the site itself are
+// rewritten to root relative paths, so the static site no longer depends on
+// Ghost.
+package staticsite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Format is the static site generator exported for.
+type Format string
+
+// Formats.
+const (
+	// Hugo writes posts to content/posts/ and images to static/.
+	Hugo Format = "hugo"
+	// Jekyll writes posts to _posts/, drafts to _drafts/ and images to the
+	// site root.
+	Jekyll Format = "jekyll"
+)
+
+// imagesPath is the path of content images, on Ghost and in the export.
+const imagesPath = "/content/images/"
+
+// pageSize is the number of posts fetched per request.
+const pageSize = 50
+
+// Options configure an export.
+type Options struct {
+	// Format is the generator exported for. Defaults to Hugo.
+	Format Format
+	// Filter limits the posts exported. Defaults to "status:published".
+	Filter string
+	// HTTPClient fetches content images, which are public. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Result describes what was exported.
+type Result struct {
+	Posts  int
+	Images int
+	// MissingImages are images referenced by posts which could not be
+	// downloaded, e.g. because they were deleted. Their references are
+	// rewritten all the same.
+	MissingImages []string
+}
+
+// Export writes the posts of the site, and the images they reference, to
+// dir as the content of a static site.
+func Export(ctx context.Context, client *ghost.AdminClient, dir string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	format := opts.Format
+	if format == "" {
+		format = Hugo
+	}
+	if format != Hugo && format != Jekyll {
+		return nil, fmt.Errorf("staticsite: unknown format %q", format)
+	}
+	filter := opts.Filter
+	if filter == "" {
+		filter = "status:published"
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	client = client.WithContext(ctx)
+	site, err := siteURL(client)
+	if err != nil {
+		return nil, err
+	}
+	e := &exporter{
+		format: format,
+		dir:    dir,
+		site:   site,
+		images: make(map[string]bool),
+	}
+	result := &Result{}
+	params := &ghost.ListParams{
+		QueryParams: ghost.QueryParams{Include: "tags,authors", Formats: "html"},
+		Filter:      filter,
+		Limit:       pageSize,
+		Page:        1,
+		Order:       "published_at asc",
+	}
+	for {
+		page, resp, err := client.Posts.List(params)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range page.Posts {
+			if err := e.writePost(post); err != nil {
+				return nil, fmt.Errorf("staticsite: post %s: %v", str(post.ID), err)
+			}
+			result.Posts++
+		}
+		if resp.Meta == nil || resp.Meta.Pagination == nil || resp.Meta.Pagination.Next == nil {
+			break
+		}
+		params.Page = *resp.Meta.Pagination.Next
+	}
+
+	var images []string
+	for p := range e.images {
+		images = append(images, p)
+	}
+	sort.Strings(images)
+	for _, p := range images {
+		err := e.downloadImage(ctx, httpClient, p)
+		if err == errImageMissing {
+			result.MissingImages = append(result.MissingImages, imagesPath+p)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("staticsite: image %s: %v", p, err)
+		}
+		result.Images++
+	}
+	return result, nil
+}
+
+// exporter holds the state of an export: the images referenced so far, by
+// path under imagesPath.
+type exporter struct {
+	format Format
+	dir    string
+	site   *url.URL
+	images map[string]bool
+}
+
+// siteURL fetches the public root url of the site, which may differ from
+// that of the admin api, e.g. www.example.com rather than admin.example.com.
+func siteURL(client *ghost.AdminClient) (*url.URL, error) {
+	req, err := client.NewRequest("GET", "site/", nil)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct{ Site *ghost.Site }
+	if _, err := client.Do(req, &wrapper); err != nil {
+		return nil, err
+	}
+	if wrapper.Site == nil || wrapper.Site.URL == nil {
+		return nil, fmt.Errorf("staticsite: site has no url")
+	}
+
+	u, err := url.Parse(*wrapper.Site.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("staticsite: invalid site url %q", *wrapper.Site.URL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
+
+var imageRef = regexp.MustCompile(`(?:__GHOST_URL__|https?://[^"'\s()<>\\]+)?/content/images/[^"'\s()<>\\?#,]+`)
+
+// localURL returns the root relative url of the content image ref, recording
+// it for download, or "" if ref is not an image of the site.
+func (e *exporter) localURL(ref string) string {
+	ref = strings.TrimPrefix(ref, "__GHOST_URL__")
+	u, err := e.site.Parse(ref)
+	if err != nil || u.Host != e.site.Host {
+		return ""
+	}
+	i := strings.Index(u.Path, imagesPath)
+	if i < 0 {
+		// e.g. a feature image elsewhere on the site.
+		return ""
+	}
+	p := path.Clean(u.Path[i+len(imagesPath):])
+	if strings.HasPrefix(p, "..") {
+		return ""
+	}
+	e.images[p] = true
+	return imagesPath + p
+}
+
+// rewrite points the content images referenced by html at their downloads,
+// and links to the site at the static site.
+func (e *exporter) rewrite(html string) string {
+	html = imageRef.ReplaceAllStringFunc(html, func(ref string) string {
+		if local := e.localURL(ref); local != "" {
+			return local
+		}
+		return ref
+	})
+	root := strings.TrimSuffix(e.site.String(), "/")
+	html = strings.Replace(html, `"`+root+`/`, `"/`, -1)
+	html = strings.Replace(html, `"`+root+`"`, `"/"`, -1)
+	return strings.Replace(html, "__GHOST_URL__/", "/", -1)
+}
+
+// permalink is the path of the post on the site, which the static site keeps.
+func (e *exporter) permalink(post *ghost.Post) string {
+	if post.URL != nil {
+		if u, err := e.site.Parse(*post.URL); err == nil && u.Host == e.site.Host && !strings.HasPrefix(u.Path, "/p/") {
+			p := strings.TrimPrefix(u.Path, strings.TrimSuffix(e.site.Path, "/"))
+			if p != "" && p != "/" {
+				return p
+			}
+		}
+	}
+	return "/" + str(post.Slug) + "/"
+}
+
+func (e *exporter) writePost(post *ghost.Post) error {
+	slug := str(post.Slug)
+	if slug == "" {
+		slug = str(post.ID)
+	}
+	if slug == "" || strings.ContainsAny(slug, `/\`) || slug == "." || slug == ".." {
+		return fmt.Errorf("invalid slug %q", slug)
+	}
+
+	var name string
+	draft := str(post.Status) != "published"
+	switch {
+	case e.format == Hugo:
+		name = path.Join("content", "posts", slug+".html")
+	case draft || post.PublishedAt == nil:
+		name = path.Join("_drafts", slug+".html")
+	default:
+		name = path.Join("_posts", post.PublishedAt.UTC().Format("2006-01-02")+"-"+slug+".html")
+	}
+
+	fm, err := yaml.Marshal(e.frontMatter(post, draft))
+	if err != nil {
+		return err
+	}
+	content := "---\n" + string(fm) + "---\n\n" + e.rewrite(str(post.HTML)) + "\n"
+	return writeFile(filepath.Join(e.dir, filepath.FromSlash(name)), content)
+}
+
+// frontMatter derives the front matter of post, using the variables of the
+// generator and its common themes.
+func (e *exporter) frontMatter(post *ghost.Post, draft bool) yaml.MapSlice {
+	var fm yaml.MapSlice
+	add := func(key string, value interface{}) {
+		fm = append(fm, yaml.MapItem{Key: key, Value: value})
+	}
+	addString := func(key string, s *string) {
+		if s != nil && *s != "" {
+			add(key, *s)
+		}
+	}
+	addTime := func(key string, t *time.Time) {
+		if t != nil {
+			add(key, t.UTC().Format(time.RFC3339))
+		}
+	}
+
+	var tags, authors []string
+	for _, tag := range post.Tags {
+		// internal tags, starting with #, are not shown by Ghost either.
+		if name := str(tag.Name); name != "" && !strings.HasPrefix(name, "#") {
+			tags = append(tags, name)
+		}
+	}
+	for _, author := range post.Authors {
+		if name := str(author.Name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	description := post.CustomExcerpt
+	if description == nil || *description == "" {
+		description = post.MetaDescription
+	}
+	var image string
+	if post.FeatureImage != nil && *post.FeatureImage != "" {
+		image = *post.FeatureImage
+		if local := e.localURL(image); local != "" {
+			image = local
+		}
+	}
+
+	addString("title", post.Title)
+	addString("slug", post.Slug)
+	addTime("date", post.PublishedAt)
+	switch e.format {
+	case Hugo:
+		addTime("lastmod", post.UpdatedAt)
+		if draft {
+			add("draft", true)
+		}
+		add("url", e.permalink(post))
+	case Jekyll:
+		add("layout", "post")
+		// posts are HTML as rendered by Ghost, in which {{ and {% are
+		// content, such as code samples, rather than Liquid.
+		add("render_with_liquid", false)
+		addTime("last_modified_at", post.UpdatedAt)
+		add("permalink", e.permalink(post))
+	}
+	if len(tags) > 0 {
+		add("tags", tags)
+	}
+	if len(authors) > 0 {
+		add("authors", authors)
+	}
+	addString("description", description)
+	if image != "" {
+		if e.format == Hugo {
+			add("images", []string{image})
+		} else {
+			add("image", image)
+		}
+	}
+	if post.Featured != nil && *post.Featured {
+		add("featured", true)
+	}
+	addString("canonical_url", post.CanonicalURL)
+	return fm
+}
+
+var errImageMissing = errors.New("image missing")
+
+// downloadImage downloads the content image at path p, unless already done
+// by an earlier export into dir.
+func (e *exporter) downloadImage(ctx context.Context, client *http.Client, p string) error {
+	local := filepath.Join(e.dir, filepath.FromSlash(imagesPath+p))
+	if e.format == Hugo {
+		local = filepath.Join(e.dir, "static", filepath.FromSlash(imagesPath+p))
+	}
+	if _, err := os.Stat(local); err == nil {
+		return nil
+	}
+
+	u, err := e.site.Parse(strings.TrimPrefix(imagesPath, "/") + p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errImageMissing
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("received %v status fetching %s", resp.StatusCode, u)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		return err
+	}
+	// images are written under a temporary name, so that a failed download
+	// is not mistaken for a finished one by the next export.
+	f, err := os.Create(local + ".part")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), local)
+}
+
+func writeFile(name, content string) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, []byte(content), 0644)
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
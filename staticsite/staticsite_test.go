@@ -0,0 +1,152 @@
+package staticsite
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pubbit-co/go-ghost"
+	"github.com/stretchr/testify/require"
+)
+
+const testPage1 = `{"posts": [{
+	"id": "1",
+	"slug": "hello",
+	"title": "Hello",
+	"status": "published",
+	"url": "%[1]s/2020/hello/",
+	"published_at": "2020-05-01T10:00:00.000+02:00",
+	"updated_at": "2020-05-02T00:00:00.000Z",
+	"feature_image": "%[1]s/content/images/2020/05/feature.png",
+	"custom_excerpt": "Hi there",
+	"featured": true,
+	"tags": [{"name": "News"}, {"name": "#internal"}],
+	"authors": [{"name": "Jane"}],
+	"html": "<p><a href=\"%[1]s/about/\">About</a> <a href=\"https://elsewhere.com/\">elsewhere</a></p><img src=\"%[1]s/content/images/2020/05/a.jpg\" srcset=\"%[1]s/content/images/size/w600/2020/05/a.jpg 600w, %[1]s/content/images/2020/05/a.jpg 1000w\"><img src=\"https://elsewhere.com/content/images/x.png\">"
+}], "meta": {"pagination": {"next": 2}}}`
+
+const testPage2 = `{"posts": [{
+	"id": "2",
+	"slug": "draft",
+	"title": "Draft",
+	"status": "draft",
+	"url": "%[1]s/p/0b9f4a/",
+	"feature_image": "%[1]s/logo.png",
+	"html": "<img src=\"/content/images/2020/05/gone.jpg\"><pre><code>{{ .Title }} {%% if x %%}</code></pre>"
+}], "meta": {"pagination": {}}}`
+
+// fakeGhost serves the Admin API and the public site, with its content
+// images, from different hosts.
+func fakeGhost(t *testing.T) (*ghost.AdminClient, func()) {
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/content/images/2020/05/gone.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, r.URL.Path)
+	}))
+
+	mux := http.NewServeMux()
+	admin := httptest.NewServer(mux)
+	mux.HandleFunc(ghost.BaseAdminPath+"site/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"site": {"title": "Blog", "url": "%s/", "version": "3.15"}}`, public.URL)
+	})
+	mux.HandleFunc(ghost.BaseAdminPath+"posts/", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "html", r.FormValue("formats"))
+		require.Equal(t, "tags,authors", r.FormValue("include"))
+		require.Equal(t, "status:[published,draft]", r.FormValue("filter"))
+		if r.FormValue("page") == "2" {
+			fmt.Fprintf(w, testPage2, public.URL)
+			return
+		}
+		fmt.Fprintf(w, testPage1, public.URL)
+	})
+
+	client, err := ghost.NewAdminClient(admin.URL, &http.Client{})
+	require.NoError(t, err)
+	return client, func() {
+		admin.Close()
+		public.Close()
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestExport_Hugo(t *testing.T) {
+	client, teardown := fakeGhost(t)
+	defer teardown()
+	dir, err := ioutil.TempDir("", "staticsite")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	result, err := Export(context.Background(), client, dir, &Options{Filter: "status:[published,draft]"})
+	require.NoError(t, err)
+	require.Equal(t, &Result{Posts: 2, Images: 3, MissingImages: []string{"/content/images/2020/05/gone.jpg"}}, result)
+
+	require.Equal(t, `---
+title: Hello
+slug: hello
+date: "2020-05-01T08:00:00Z"
+lastmod: "2020-05-02T00:00:00Z"
+url: /2020/hello/
+tags:
+- News
+authors:
+- Jane
+description: Hi there
+images:
+- /content/images/2020/05/feature.png
+featured: true
+---
+
+<p><a href="/about/">About</a> <a href="https://elsewhere.com/">elsewhere</a></p><img src="/content/images/2020/05/a.jpg" srcset="/content/images/size/w600/2020/05/a.jpg 600w, /content/images/2020/05/a.jpg 1000w"><img src="https://elsewhere.com/content/images/x.png">
+`, readFile(t, dir, "content/posts/hello.html"))
+	draft := readFile(t, dir, "content/posts/draft.html")
+	require.Contains(t, draft, "draft: true\nurl: /draft/\n")
+	require.Contains(t, draft, "/logo.png\n")
+	require.Equal(t, "/content/images/size/w600/2020/05/a.jpg", readFile(t, dir, "static/content/images/size/w600/2020/05/a.jpg"))
+	require.Equal(t, "/content/images/2020/05/feature.png", readFile(t, dir, "static/content/images/2020/05/feature.png"))
+}
+
+func TestExport_Jekyll(t *testing.T) {
+	client, teardown := fakeGhost(t)
+	defer teardown()
+	dir, err := ioutil.TempDir("", "staticsite")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = Export(context.Background(), client, dir, &Options{Format: Jekyll, Filter: "status:[published,draft]"})
+	require.NoError(t, err)
+
+	hello := readFile(t, dir, "_posts/2020-05-01-hello.html")
+	require.Contains(t, hello, "layout: post\nrender_with_liquid: false\nlast_modified_at: \"2020-05-02T00:00:00Z\"\npermalink: /2020/hello/\n")
+	require.Contains(t, hello, "image: /content/images/2020/05/feature.png\n")
+	require.Contains(t, readFile(t, dir, "_drafts/draft.html"), `<img src="/content/images/2020/05/gone.jpg"><pre><code>{{ .Title }} {% if x %}</code></pre>`)
+	require.Equal(t, "/content/images/2020/05/a.jpg", readFile(t, dir, "content/images/2020/05/a.jpg"))
+
+	_, err = Export(context.Background(), client, dir, &Options{Format: "gatsby"})
+	require.Error(t, err)
+}
+
+func TestLocalURL(t *testing.T) {
+	site, _ := url.Parse("https://example.com/")
+	e := &exporter{site: site, images: make(map[string]bool)}
+
+	require.Equal(t, "/content/images/2020/05/a.jpg", e.localURL("https://example.com/content/images/2020/05/a.jpg"))
+	require.Equal(t, "/content/images/b.png", e.localURL("__GHOST_URL__/content/images/b.png"))
+	// images of the site outside /content/images/, such as a logo in the
+	// theme, and of other sites are left alone.
+	require.Equal(t, "", e.localURL("https://example.com/logo.png"))
+	require.Equal(t, "", e.localURL("https://elsewhere.com/content/images/x.png"))
+	require.Equal(t, map[string]bool{"2020/05/a.jpg": true, "b.png": true}, e.images)
+}
@@ -113,5 +113,6 @@ func setupTestUser() error {
 		Password:  testPassword,
 		BlogTitle: testBlogTitle,
 	}
-	return client.Authentication.Setup(details)
+	_, err = client.Authentication.Setup(details)
+	return err
 }
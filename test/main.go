@@ -27,7 +27,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	err = client.Session.Create("testing@testing.com", "testing123")
+	_, err = client.Session.Create("testing@testing.com", "testing123")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -38,7 +38,7 @@ func main() {
 	//cookies := jar.Cookies(u)
 	//fmt.Println(cookies)
 
-	db, err := client.Database.Export()
+	db, _, err := client.Database.Export()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -49,7 +49,7 @@ func main() {
 	}
 	keyHttpClient := oauth2.NewClient(context.Background(), ts)
 	keyClient, err := ghost.NewAdminClient("http://localhost:2369", keyHttpClient)
-	problems, err := keyClient.Database.Import(db)
+	problems, _, err := keyClient.Database.Import(db)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -58,7 +58,7 @@ func main() {
 	}
 
 	/*
-		redirects, err := client.Redirects.Download()
+		redirects, _, err := client.Redirects.Download()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -67,7 +67,7 @@ func main() {
 			From: "/hello",
 			To:   "/",
 		})
-		err = client.Redirects.Upload(redirects)
+		_, err = client.Redirects.Upload(redirects)
 		if err != nil {
 			log.Fatal(err)
 		}*/
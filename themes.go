@@ -0,0 +1,102 @@
+package ghost
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+)
+
+// AdminThemesService handles listing, downloading and uploading themes.
+type AdminThemesService adminService
+
+// Theme is an installed theme.
+type Theme struct {
+	Name   *string `json:"name"`
+	Active *bool   `json:"active"`
+}
+
+func (t Theme) String() string {
+	return Stringify(t)
+}
+
+type themesWrapper struct {
+	Themes []*Theme `json:"themes"`
+}
+
+// List fetches the installed themes.
+func (s *AdminThemesService) List() ([]*Theme, *Response, error) {
+	req, err := s.client.NewRequest("GET", "themes/", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapper := new(themesWrapper)
+	resp, err := s.client.Do(req, wrapper)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+
+	return wrapper.Themes, newResponse(resp), nil
+}
+
+// Download writes the zip archive of the named theme to w.
+func (s *AdminThemesService) Download(name string, w io.Writer) (*Response, error) {
+	u := fmt.Sprintf("themes/%v/download", name)
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req, w)
+	return newResponse(resp), err
+}
+
+// Upload installs the theme zip archive read from r. The theme name is taken
+// from filename, e.g. "casper.zip", and an existing theme of the same name
+// is overwritten.
+func (s *AdminThemesService) Upload(filename string, r io.Reader) (*Theme, *Response, error) {
+	themeWriter := func(mpw *multipart.Writer) error {
+		part, err := createFormFile(mpw, "file", filename, "application/zip")
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, r)
+		return err
+	}
+
+	req, err := s.client.NewStreamingUploadRequest("themes/upload", themeWriter, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapper := new(themesWrapper)
+	resp, err := s.client.Do(req, wrapper)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+	if len(wrapper.Themes) != 1 {
+		return nil, newResponse(resp), fmt.Errorf("received unexpected response format")
+	}
+
+	return wrapper.Themes[0], newResponse(resp), nil
+}
+
+// Activate makes the named theme the active theme.
+func (s *AdminThemesService) Activate(name string) (*Theme, *Response, error) {
+	u := fmt.Sprintf("themes/%v/activate", name)
+	req, err := s.client.NewRequest("PUT", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapper := new(themesWrapper)
+	resp, err := s.client.Do(req, wrapper)
+	if err != nil {
+		return nil, newResponse(resp), err
+	}
+	if len(wrapper.Themes) != 1 {
+		return nil, newResponse(resp), fmt.Errorf("received unexpected response format")
+	}
+
+	return wrapper.Themes[0], newResponse(resp), nil
+}
@@ -1,22 +1,41 @@
 package ghost
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"golang.org/x/oauth2"
 )
 
 const (
 	tokenAudience = "/v3/admin/"
 	tokenType     = "Ghost"
+	tokenLifetime = time.Minute * 5
 	timeout       = time.Second * 10
 )
 
+// jwtHeader is the JOSE header of the admin token. Field order matters, as it
+// determines the exact bytes that get signed.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims are the claims Ghost expects in the admin token.
+type jwtClaims struct {
+	Audience  string `json:"aud"`
+	ExpiresAt int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+}
+
 // AdminTokenSource is a token source for token-based authentication with
 // the Ghost Admin API.
 type AdminTokenSource struct {
@@ -36,25 +55,43 @@ func (ats *AdminTokenSource) Token() (*oauth2.Token, error) {
 		return nil, fmt.Errorf("secret portion of key not valid hex")
 	}
 
-	claims := &jwt.StandardClaims{
-		Audience:  tokenAudience,
-		IssuedAt:  time.Now().Unix(),
-		ExpiresAt: time.Now().Unix() + (5 * 60),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token.Header["kid"] = kid
-	ss, err := token.SignedString(secretBytes)
+	now := time.Now()
+	ss, err := signAdminToken(kid, secretBytes, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create auth token: %v", err)
 	}
 
 	return &oauth2.Token{
 		AccessToken: ss,
-		Expiry:      time.Now().Add(time.Minute * 5),
+		Expiry:      now.Add(tokenLifetime),
 		TokenType:   tokenType,
 	}, nil
 }
 
+// signAdminToken creates the HS256 signed JWT for the key id and secret,
+// issued at the provided time.
+func signAdminToken(kid string, secret []byte, issuedAt time.Time) (string, error) {
+	header, err := json.Marshal(&jwtHeader{Alg: "HS256", Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(&jwtClaims{
+		Audience:  tokenAudience,
+		ExpiresAt: issuedAt.Add(tokenLifetime).Unix(),
+		IssuedAt:  issuedAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingString := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingString))
+	return signingString + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
 // NewAdminTokenSource returns a reusable oauth2.TokenSource that is backed by
 // the AdminTokenSource implementation. It handles properly creating and renewing
 // the JWT needed for communication with Ghost for token-based auth.
@@ -1,6 +1,7 @@
 package ghost
 
 import (
+	"encoding/hex"
 	"testing"
 	"time"
 
@@ -20,3 +21,18 @@ func TestAdminTokenSource(t *testing.T) {
 	require.True(t, time.Now().Add(time.Minute*5).After(tok.Expiry))
 	require.Equal(t, "Ghost", tok.TokenType)
 }
+
+func TestSignAdminToken_golden(t *testing.T) {
+	// produced by github.com/dgrijalva/jwt-go for the same key and timestamp,
+	// prior to replacing it with the in-house signer.
+	const want = "eyJhbGciOiJIUzI1NiIsImtpZCI6IjVlYTFhZWIxN2VkYzI2NTA0NjhiNjU1NCIsInR5cCI6IkpXVCJ9." +
+		"eyJhdWQiOiIvdjMvYWRtaW4vIiwiZXhwIjoxNTg4MDAwMzAwLCJpYXQiOjE1ODgwMDAwMDB9." +
+		"LLcFem2FKgtE9VLzXFFjvPqrh91GHk5Uzslgz1Itmfo"
+
+	secret, err := hex.DecodeString("0f1103f5af0395a73041457eb6928f9e0d143a8dcba187915342e65687e2a589")
+	require.NoError(t, err)
+
+	got, err := signAdminToken("5ea1aeb17edc2650468b6554", secret, time.Unix(1588000000, 0))
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -0,0 +1,167 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Problem messages reported by Validate.
+const (
+	problemMissingMeta      = "Export has no version information."
+	problemNewerVersion     = "Export is from a newer major version of Ghost than the target site."
+	problemDuplicateID      = "Duplicate id found. Entry would not be imported."
+	problemDuplicateSlug    = "Duplicate slug found. Entry would not be imported."
+	problemMissingReference = "Entry references a missing record and would not be imported."
+	problemInvalidMobiledoc = "Post has invalid mobiledoc JSON."
+	problemInvalidLexical   = "Post has invalid lexical JSON."
+)
+
+// Validate checks db for problems that would cause Ghost to reject or skip
+// parts of it on import, without uploading it: a version newer than
+// targetVersion, duplicate ids and slugs, posts_tags, posts_authors and
+// roles_users rows referencing missing records, and posts with invalid
+// mobiledoc or lexical. An empty targetVersion skips the version check.
+// Problems are reported in the shape Ghost uses for import problems, with Help
+// naming the kind of record and Context holding the offending row as JSON.
+func (db *Database) Validate(targetVersion string) []*DatabaseImportProblem {
+	v := &validator{}
+
+	if db.Meta == nil || db.Meta.Version == "" {
+		v.add(problemMissingMeta, "Meta", db.Meta, "ValidationError")
+	} else if targetVersion != "" && majorVersion(db.Meta.Version) > majorVersion(targetVersion) {
+		v.add(problemNewerVersion, "Meta", db.Meta, "IncorrectUsageError")
+	}
+
+	data := db.Data
+	if data == nil {
+		return v.problems
+	}
+
+	postIDs := v.unique("Post", len(data.Posts), func(i int) (*string, *string, interface{}) {
+		return data.Posts[i].ID, data.Posts[i].Slug, data.Posts[i]
+	})
+	tagIDs := v.unique("Tag", len(data.Tags), func(i int) (*string, *string, interface{}) {
+		return data.Tags[i].ID, data.Tags[i].Slug, data.Tags[i]
+	})
+	userIDs := v.unique("User", len(data.Users), func(i int) (*string, *string, interface{}) {
+		return data.Users[i].ID, data.Users[i].Slug, data.Users[i]
+	})
+	roleIDs := v.unique("Role", len(data.Roles), func(i int) (*string, *string, interface{}) {
+		return data.Roles[i].ID, nil, data.Roles[i]
+	})
+
+	for _, post := range data.Posts {
+		if post.Mobiledoc != nil && !json.Valid([]byte(*post.Mobiledoc)) {
+			v.add(problemInvalidMobiledoc, "Post", post, "ValidationError")
+		}
+		if post.Lexical != nil && !json.Valid([]byte(*post.Lexical)) {
+			v.add(problemInvalidLexical, "Post", post, "ValidationError")
+		}
+	}
+	for _, pt := range data.PostsTags {
+		if !postIDs[deref(pt.PostID)] || !tagIDs[deref(pt.TagID)] {
+			v.add(problemMissingReference, "PostTag", pt, "NotFoundError")
+		}
+	}
+	for _, pa := range data.PostsAuthors {
+		if !postIDs[deref(pa.PostID)] || !userIDs[deref(pa.AuthorID)] {
+			v.add(problemMissingReference, "PostAuthor", pa, "NotFoundError")
+		}
+	}
+	for _, ru := range data.RolesUsers {
+		if !roleIDs[deref(ru.RoleID)] || !userIDs[deref(ru.UserID)] {
+			v.add(problemMissingReference, "RoleUser", ru, "NotFoundError")
+		}
+	}
+
+	return v.problems
+}
+
+type validator struct {
+	problems []*DatabaseImportProblem
+}
+
+func (v *validator) add(message, help string, entry interface{}, errorType string) {
+	context, _ := json.Marshal(entry)
+	problem := &DatabaseImportProblem{
+		Message: message,
+		Help:    help,
+		Context: string(context),
+		Err: map[string]interface{}{
+			"errorType": errorType,
+			"message":   message,
+		},
+	}
+	problem.parse()
+	v.problems = append(v.problems, problem)
+}
+
+// unique reports duplicate ids and slugs among the n rows of a table, as
+// returned by row, and returns the set of ids.
+func (v *validator) unique(help string, n int, row func(i int) (id, slug *string, entry interface{})) map[string]bool {
+	ids := make(map[string]bool, n)
+	slugs := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		id, slug, entry := row(i)
+		if id != nil {
+			if ids[*id] {
+				v.add(problemDuplicateID, help, entry, "ValidationError")
+			}
+			ids[*id] = true
+		}
+		if slug != nil {
+			if slugs[*slug] {
+				v.add(problemDuplicateSlug, help, entry, "ValidationError")
+			}
+			slugs[*slug] = true
+		}
+	}
+	return ids
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// majorVersion returns the major component of a version such as "3.15.1" or
+// "v3.15", or 0 if it cannot be parsed.
+func majorVersion(version string) int {
+	version = strings.TrimPrefix(version, "v")
+	major, _ := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	return major
+}
+
+// DryRun validates db against the version of the site, as Import would
+// receive it, without uploading anything. See Database.Validate.
+func (s *AdminDatabaseService) DryRun(db *Database) ([]*DatabaseImportProblem, *Response, error) {
+	version, resp, err := s.siteVersion()
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return db.Validate(version), resp, nil
+}
+
+// siteVersion fetches the Ghost version of the site.
+func (s *AdminDatabaseService) siteVersion() (string, *Response, error) {
+	req, err := s.client.NewRequest("GET", "site/", nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	wrapper := new(siteWrapper)
+	resp, err := s.client.Do(req, wrapper)
+	if err != nil {
+		return "", newResponse(resp), err
+	}
+	if wrapper.Site == nil || wrapper.Site.Version == nil {
+		return "", newResponse(resp), fmt.Errorf("received unexpected response format")
+	}
+
+	return *wrapper.Site.Version, newResponse(resp), nil
+}
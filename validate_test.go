@@ -0,0 +1,62 @@
+package ghost
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_Validate(t *testing.T) {
+	db := &Database{
+		Meta: &DatabaseMeta{Version: "4.1.0"},
+		Data: &DatabaseData{
+			Posts: []*PostRow{
+				{ID: String("p1"), Slug: String("hello"), Mobiledoc: String(`{"version":"0.3.1"}`)},
+				{ID: String("p2"), Slug: String("hello"), Mobiledoc: String(`{"version":`), Lexical: String(`{"root":`)},
+			},
+			Tags:  []*TagRow{{ID: String("t1"), Slug: String("news")}},
+			Users: []*UserRow{{ID: String("u1"), Slug: String("ghost")}},
+			PostsTags: []*PostTagRow{
+				{PostID: String("p1"), TagID: String("t1")},
+				{PostID: String("p1"), TagID: String("t2")},
+			},
+			PostsAuthors: []*PostAuthorRow{
+				{PostID: String("p3"), AuthorID: String("u1")},
+			},
+		},
+	}
+
+	problems := db.Validate("3.15")
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, fmt.Sprintf("%s %s", problem.Help, problem.Message))
+	}
+	require.Equal(t, []string{
+		"Meta " + problemNewerVersion,
+		"Post " + problemDuplicateSlug,
+		"Post " + problemInvalidMobiledoc,
+		"Post " + problemInvalidLexical,
+		"PostTag " + problemMissingReference,
+		"PostAuthor " + problemMissingReference,
+	}, messages)
+	require.Equal(t, `{"post_id":"p1","tag_id":"t2"}`, problems[4].Context)
+	require.Equal(t, "NotFoundError", problems[4].Err["errorType"])
+
+	require.Len(t, db.Validate("4.0"), 5)
+}
+
+func TestDatabaseService_DryRun(t *testing.T) {
+	client, mux, _, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(BaseAdminPath+"site/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"site": {"title": "Blog", "version": "3.15"}}`)
+	})
+
+	problems, _, err := client.Database.DryRun(&Database{Meta: &DatabaseMeta{Version: "3.2.0"}})
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
@@ -0,0 +1,70 @@
+package wordpress
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pubbit-co/go-ghost/mobiledoc"
+)
+
+var (
+	blockComment = regexp.MustCompile(`<!--\s*/?wp:[^>]*-->`)
+	caption      = regexp.MustCompile(`(?s)\[caption[^\]]*\]\s*((?:<a[^>]*>\s*)?<img[^>]*>(?:\s*</a>)?)(.*?)\[/caption\]`)
+	preBlock     = regexp.MustCompile(`(?is)<pre[\s>].*?</pre>`)
+	blankLines   = regexp.MustCompile(`\n\s*\n`)
+	blockTag     = regexp.MustCompile(`(?i)^<(?:p|div|h[1-6]|ul|ol|li|dl|blockquote|pre|figure|table|hr|iframe|script|style|form|section|address|article|aside|video|audio)[\s/>]`)
+)
+
+// convertHTML turns WordPress post content into the HTML Ghost expects: block
+// editor comments are stripped, [caption] shortcodes become figures, and the
+// paragraphs WordPress adds when rendering, from blank lines and line breaks,
+// are made explicit.
+func convertHTML(content string) string {
+	content = strings.Replace(content, "\r\n", "\n", -1)
+	content = blockComment.ReplaceAllString(content, "")
+	content = caption.ReplaceAllStringFunc(content, func(match string) string {
+		parts := caption.FindStringSubmatch(match)
+		figure := "<figure>" + parts[1]
+		if text := strings.TrimSpace(parts[2]); text != "" {
+			figure += "<figcaption>" + text + "</figcaption>"
+		}
+		return figure + "</figure>"
+	})
+
+	// preformatted blocks keep their blank lines and line breaks.
+	var pres []string
+	content = preBlock.ReplaceAllStringFunc(content, func(match string) string {
+		pres = append(pres, match)
+		return fmt.Sprintf("\x00%d\x00", len(pres)-1)
+	})
+
+	var blocks []string
+	for _, block := range blankLines.Split(content, -1) {
+		block = strings.TrimSpace(block)
+		switch {
+		case block == "":
+			continue
+		case blockTag.MatchString(block), strings.HasPrefix(block, "\x00"):
+			blocks = append(blocks, block)
+		default:
+			blocks = append(blocks, "<p>"+strings.Replace(block, "\n", "<br>\n", -1)+"</p>")
+		}
+	}
+	content = strings.Join(blocks, "\n")
+
+	for i, pre := range pres {
+		content = strings.Replace(content, fmt.Sprintf("\x00%d\x00", i), pre, 1)
+	}
+	return content
+}
+
+// htmlMobiledoc wraps html in the mobiledoc of a single HTML card, which is
+// how Ghost itself imports HTML content.
+func htmlMobiledoc(html string) (string, error) {
+	doc, err := mobiledoc.NewBuilder().Card(mobiledoc.HTMLCard{HTML: html}).Document()
+	if err != nil {
+		return "", err
+	}
+	return doc.Marshal()
+}
@@ -0,0 +1,330 @@
+// Package wordpress converts WordPress WXR exports into Ghost database
+// exports, along with the redirects from the old WordPress permalinks.
+package wordpress
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pubbit-co/go-ghost"
+)
+
+// GhostVersion is the version written to the meta of converted exports.
+const GhostVersion = "3.0.0"
+
+// wpTimeFormat is the form of timestamps in WXR exports.
+const wpTimeFormat = "2006-01-02 15:04:05"
+
+// exportTimeFormat is the form of timestamps in Ghost exports.
+const exportTimeFormat = "2006-01-02T15:04:05.000Z"
+
+// Result is a converted WXR export.
+type Result struct {
+	DB *ghost.Database
+	// Redirects map the paths of the old WordPress permalinks to their Ghost
+	// URLs, for published posts and pages, categories and authors whose
+	// paths differ.
+	Redirects []*ghost.Redirect
+	// Skipped holds the ids of items left out, such as trashed posts,
+	// revisions and custom post types.
+	Skipped []string
+}
+
+// Convert reads a WXR export from r. Published posts and pages are imported
+// as published, scheduled ones as scheduled and everything else as drafts.
+// Categories and tags both become Ghost tags. Authors get the Author role;
+// those without an email address get one at example.com, to be corrected
+// after import. Featured images and content keep pointing at the WordPress
+// uploads, which need moving separately.
+func Convert(r io.Reader) (*Result, error) {
+	var export wxr
+	if err := xml.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	c := &converter{
+		data:        &ghost.DatabaseData{},
+		result:      &Result{},
+		authors:     make(map[string]string),
+		tags:        make(map[string]string),
+		attachments: make(map[string]string),
+		slugs:       make(map[string]bool),
+		owners:      make(map[string]string),
+	}
+	c.result.DB = &ghost.Database{
+		Meta: &ghost.DatabaseMeta{Version: GhostVersion, ExportedOn: time.Now().UnixNano() / int64(time.Millisecond)},
+		Data: c.data,
+	}
+
+	role := ghost.String(id("role", "author"))
+	c.data.Roles = []*ghost.RoleRow{{ID: role, Name: ghost.String("Author")}}
+	for _, author := range export.Channel.Authors {
+		c.addAuthor(author, role)
+	}
+	for _, category := range export.Channel.Categories {
+		c.addTag(category.Slug, category.Name, category.Description)
+		c.redirect("/category/"+category.Slug+"/", "/tag/"+slugify(category.Slug)+"/")
+	}
+	for _, tag := range export.Channel.Tags {
+		c.addTag(tag.Slug, tag.Name, tag.Description)
+	}
+
+	for _, item := range export.Channel.Items {
+		if item.Type == "attachment" {
+			c.attachments[item.ID] = item.AttachmentURL
+		}
+	}
+	// items whose permalink already is /slug/ keep their slug, so that
+	// their URL does not change and no redirect shadows it.
+	for _, item := range export.Channel.Items {
+		slug := itemSlug(&item)
+		if link, err := url.Parse(item.Link); err == nil && converted(&item) && link.Path == "/"+slug+"/" {
+			c.owners[slug] = item.ID
+			c.slugs[slug] = true
+		}
+	}
+	for _, item := range export.Channel.Items {
+		if err := c.addItem(&item); err != nil {
+			return nil, err
+		}
+	}
+	return c.result, nil
+}
+
+type converter struct {
+	data   *ghost.DatabaseData
+	result *Result
+
+	// authors maps logins to user ids, tags slugs to tag ids and
+	// attachments post ids to their URLs. slugs holds the slugs taken by
+	// posts and pages, which share a namespace in Ghost, and owners maps
+	// slugs to the ids of the items they are reserved for.
+	authors     map[string]string
+	tags        map[string]string
+	attachments map[string]string
+	slugs       map[string]bool
+	owners      map[string]string
+}
+
+// id derives a stable Ghost id from a WordPress identifier, so that
+// converting the same export twice gives the same ids.
+func id(kind, wpID string) string {
+	sum := sha1.Sum([]byte(kind + ":" + wpID))
+	return hex.EncodeToString(sum[:])[:24]
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(s string) string {
+	if unescaped, err := url.PathUnescape(s); err == nil {
+		s = unescaped
+	}
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return ghost.String(s)
+}
+
+func exportTime(wp string) *string {
+	t, err := time.Parse(wpTimeFormat, wp)
+	if err != nil {
+		return nil
+	}
+	return ghost.String(t.Format(exportTimeFormat))
+}
+
+func (c *converter) redirect(from, to string) {
+	from = strings.TrimSuffix(from, "/") + "/"
+	if from == to || from == "/" {
+		return
+	}
+	c.result.Redirects = append(c.result.Redirects, &ghost.Redirect{
+		From: "^" + regexp.QuoteMeta(strings.TrimSuffix(from, "/")) + "/?$",
+		To:   to,
+	})
+}
+
+// freeSlug returns slug, or if taken, the first of slug-2, slug-3 and so on
+// which is not.
+func freeSlug(taken map[string]bool, slug string) string {
+	if !taken[slug] {
+		return slug
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", slug, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+func (c *converter) addAuthor(author wxrAuthor, role *string) {
+	userID := ghost.String(id("user", author.Login))
+	slug := slugify(author.Login)
+	name := author.DisplayName
+	if name == "" {
+		name = author.Login
+	}
+	email := author.Email
+	if email == "" {
+		email = slug + "@example.com"
+	}
+
+	c.authors[author.Login] = *userID
+	c.data.Users = append(c.data.Users, &ghost.UserRow{
+		ID:     userID,
+		Name:   ghost.String(name),
+		Slug:   ghost.String(slug),
+		Email:  ghost.String(email),
+		Status: ghost.String("active"),
+	})
+	c.data.RolesUsers = append(c.data.RolesUsers, &ghost.RoleUserRow{
+		ID:     ghost.String(id("roles_users", author.Login)),
+		RoleID: role,
+		UserID: userID,
+	})
+	c.redirect("/author/"+author.Login+"/", "/author/"+slug+"/")
+}
+
+func (c *converter) addTag(slug, name, description string) string {
+	slug = slugify(slug)
+	if tagID, ok := c.tags[slug]; ok {
+		return tagID
+	}
+	if name == "" {
+		name = slug
+	}
+
+	tagID := id("tag", slug)
+	c.tags[slug] = tagID
+	c.data.Tags = append(c.data.Tags, &ghost.TagRow{
+		ID:          ghost.String(tagID),
+		Name:        ghost.String(name),
+		Slug:        ghost.String(slug),
+		Description: optional(description),
+	})
+	return tagID
+}
+
+// statuses maps WordPress post statuses to Ghost's; items of other statuses,
+// like trash and auto-draft, are skipped.
+var statuses = map[string]string{
+	"publish": "published",
+	"future":  "scheduled",
+	"draft":   "draft",
+	"pending": "draft",
+	"private": "draft",
+}
+
+// converted reports whether item becomes a Ghost post or page.
+func converted(item *wxrItem) bool {
+	_, ok := statuses[item.Status]
+	return ok && (item.Type == "post" || item.Type == "page")
+}
+
+func itemSlug(item *wxrItem) string {
+	slug := slugify(item.Name)
+	if slug == "" {
+		slug = slugify(item.Title)
+	}
+	if slug == "" {
+		slug = "untitled-" + item.ID
+	}
+	return slug
+}
+
+func (c *converter) addItem(item *wxrItem) error {
+	if !converted(item) {
+		if item.Type != "attachment" {
+			c.result.Skipped = append(c.result.Skipped, item.ID)
+		}
+		return nil
+	}
+	status := statuses[item.Status]
+
+	// WordPress allows a post and a page, or pages under different parents,
+	// to share a slug, which Ghost does not.
+	slug := itemSlug(item)
+	if c.owners[slug] != item.ID {
+		slug = freeSlug(c.slugs, slug)
+		c.slugs[slug] = true
+	}
+
+	html := convertHTML(item.content())
+	mobiledoc, err := htmlMobiledoc(html)
+	if err != nil {
+		return err
+	}
+
+	postID := ghost.String(id("post", item.ID))
+	post := &ghost.PostRow{
+		ID:            postID,
+		Title:         ghost.String(item.Title),
+		Slug:          ghost.String(slug),
+		Mobiledoc:     ghost.String(mobiledoc),
+		HTML:          ghost.String(html),
+		Type:          ghost.String(item.Type),
+		Status:        ghost.String(status),
+		Visibility:    ghost.String("public"),
+		Featured:      ghost.NewFlag(item.Sticky == "1"),
+		CustomExcerpt: optional(strings.TrimSpace(item.excerpt())),
+		CreatedAt:     exportTime(item.DateGMT),
+		UpdatedAt:     exportTime(item.ModifiedGMT),
+		FeatureImage:  optional(c.attachments[item.meta("_thumbnail_id")]),
+	}
+	if post.CreatedAt == nil {
+		// drafts have no GMT date until published.
+		post.CreatedAt = exportTime(item.Date)
+	}
+	if status != "draft" {
+		post.PublishedAt = post.CreatedAt
+	}
+	if authorID, ok := c.authors[item.Creator]; ok {
+		post.AuthorID = ghost.String(authorID)
+		c.data.PostsAuthors = append(c.data.PostsAuthors, &ghost.PostAuthorRow{
+			ID:        ghost.String(id("posts_authors", item.ID)),
+			PostID:    postID,
+			AuthorID:  post.AuthorID,
+			SortOrder: ghost.Int(0),
+		})
+	}
+	c.data.Posts = append(c.data.Posts, post)
+
+	tagged := make(map[string]bool)
+	sortOrder := 0
+	for _, term := range item.Terms {
+		if term.Domain != "category" && term.Domain != "post_tag" {
+			continue
+		}
+		tagID := c.addTag(term.Nicename, term.Name, "")
+		if tagged[tagID] {
+			continue
+		}
+		tagged[tagID] = true
+		c.data.PostsTags = append(c.data.PostsTags, &ghost.PostTagRow{
+			ID:        ghost.String(id("posts_tags", item.ID+":"+tagID)),
+			PostID:    postID,
+			TagID:     ghost.String(tagID),
+			SortOrder: ghost.Int(sortOrder),
+		})
+		sortOrder++
+	}
+
+	if status == "published" {
+		if link, err := url.Parse(item.Link); err == nil && link.RawQuery == "" {
+			c.redirect(link.Path, "/"+slug+"/")
+		}
+	}
+	return nil
+}
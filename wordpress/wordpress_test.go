@@ -0,0 +1,205 @@
+package wordpress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Old Blog</title>
+	<link>https://old.example.com</link>
+	<wp:author>
+		<wp:author_id>1</wp:author_id>
+		<wp:author_login><![CDATA[jane]]></wp:author_login>
+		<wp:author_email><![CDATA[jane@example.org]]></wp:author_email>
+		<wp:author_display_name><![CDATA[Jane Doe]]></wp:author_display_name>
+	</wp:author>
+	<wp:category>
+		<wp:term_id>2</wp:term_id>
+		<wp:category_nicename><![CDATA[news]]></wp:category_nicename>
+		<wp:cat_name><![CDATA[News]]></wp:cat_name>
+	</wp:category>
+	<wp:tag>
+		<wp:term_id>3</wp:term_id>
+		<wp:tag_slug><![CDATA[go]]></wp:tag_slug>
+		<wp:tag_name><![CDATA[Go]]></wp:tag_name>
+	</wp:tag>
+	<item>
+		<title>Hello World</title>
+		<link>https://old.example.com/2020/05/hello-world/</link>
+		<dc:creator><![CDATA[jane]]></dc:creator>
+		<content:encoded><![CDATA[First line
+second line
+
+[caption id="attachment_9" align="alignnone"]<img src="https://old.example.com/wp-content/uploads/a.jpg"> A caption[/caption]
+
+<!-- wp:paragraph -->
+<p>Block content</p>
+<!-- /wp:paragraph -->
+
+<pre>code
+
+more code</pre>]]></content:encoded>
+		<excerpt:encoded><![CDATA[An excerpt]]></excerpt:encoded>
+		<wp:post_id>10</wp:post_id>
+		<wp:post_date><![CDATA[2020-05-01 12:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2020-05-01 10:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[hello-world]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<wp:is_sticky>1</wp:is_sticky>
+		<category domain="category" nicename="news"><![CDATA[News]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<category domain="post_tag" nicename="new-tag"><![CDATA[New Tag]]></category>
+		<wp:postmeta>
+			<wp:meta_key><![CDATA[_thumbnail_id]]></wp:meta_key>
+			<wp:meta_value><![CDATA[9]]></wp:meta_value>
+		</wp:postmeta>
+	</item>
+	<item>
+		<title>About</title>
+		<link>https://old.example.com/about/</link>
+		<dc:creator><![CDATA[jane]]></dc:creator>
+		<content:encoded><![CDATA[About us]]></content:encoded>
+		<wp:post_id>11</wp:post_id>
+		<wp:post_date_gmt><![CDATA[2020-05-02 10:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[about]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>Unfinished</title>
+		<link>https://old.example.com/?p=12</link>
+		<dc:creator><![CDATA[jane]]></dc:creator>
+		<wp:post_id>12</wp:post_id>
+		<wp:post_date><![CDATA[2020-05-03 09:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:post_date_gmt>
+		<wp:status><![CDATA[draft]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>a.jpg</title>
+		<wp:post_id>9</wp:post_id>
+		<wp:status><![CDATA[inherit]]></wp:status>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://old.example.com/wp-content/uploads/a.jpg]]></wp:attachment_url>
+	</item>
+	<item>
+		<title>Menu</title>
+		<wp:post_id>13</wp:post_id>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[nav_menu_item]]></wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+func TestConvert(t *testing.T) {
+	result, err := Convert(strings.NewReader(testWXR))
+	require.NoError(t, err)
+	require.Empty(t, result.DB.Validate(""))
+	require.Equal(t, []string{"13"}, result.Skipped)
+
+	data := result.DB.Data
+	require.Len(t, data.Users, 1)
+	require.Equal(t, "Jane Doe", *data.Users[0].Name)
+	require.Len(t, data.RolesUsers, 1)
+	require.Len(t, data.Tags, 3)
+
+	require.Len(t, data.Posts, 3)
+	post := data.Posts[0]
+	require.Equal(t, "hello-world", *post.Slug)
+	require.Equal(t, "published", *post.Status)
+	require.Equal(t, "2020-05-01T10:00:00.000Z", *post.PublishedAt)
+	require.Equal(t, "https://old.example.com/wp-content/uploads/a.jpg", *post.FeatureImage)
+	require.Equal(t, "An excerpt", *post.CustomExcerpt)
+	require.True(t, post.Featured.Value)
+	require.Equal(t, `<p>First line<br>
+second line</p>
+<figure><img src="https://old.example.com/wp-content/uploads/a.jpg"><figcaption>A caption</figcaption></figure>
+<p>Block content</p>
+<pre>code
+
+more code</pre>`, *post.HTML)
+	require.Contains(t, *post.Mobiledoc, `"cards":[["html",{"html":"<p>First line<br>\nsecond line</p>`)
+
+	require.Equal(t, "page", *data.Posts[1].Type)
+	draft := data.Posts[2]
+	require.Equal(t, "unfinished", *draft.Slug)
+	require.Equal(t, "draft", *draft.Status)
+	require.Equal(t, "2020-05-03T09:00:00.000Z", *draft.CreatedAt)
+	require.Nil(t, draft.PublishedAt)
+
+	require.Len(t, data.PostsTags, 3)
+	require.Len(t, data.PostsAuthors, 3)
+
+	redirects := make(map[string]string)
+	for _, r := range result.Redirects {
+		redirects[r.From] = r.To
+	}
+	require.Equal(t, map[string]string{
+		`^/category/news/?$`:       "/tag/news/",
+		`^/2020/05/hello-world/?$`: "/hello-world/",
+	}, redirects)
+}
+
+const testDuplicateSlugsWXR = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<item>
+		<title>Team</title>
+		<link>https://old.example.com/company/team/</link>
+		<wp:post_id>1</wp:post_id>
+		<wp:post_date_gmt><![CDATA[2020-05-01 10:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[team]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>Team</title>
+		<link>https://old.example.com/projects/team/</link>
+		<wp:post_id>2</wp:post_id>
+		<wp:post_date_gmt><![CDATA[2020-05-01 10:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[team]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>Team</title>
+		<link>https://old.example.com/team/</link>
+		<wp:post_id>3</wp:post_id>
+		<wp:post_date_gmt><![CDATA[2020-05-01 10:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[team]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+func TestConvert_duplicateSlugs(t *testing.T) {
+	result, err := Convert(strings.NewReader(testDuplicateSlugsWXR))
+	require.NoError(t, err)
+
+	var slugs []string
+	for _, post := range result.DB.Data.Posts {
+		slugs = append(slugs, *post.Slug)
+	}
+	// the post already at /team/ keeps its slug.
+	require.Equal(t, []string{"team-2", "team-3", "team"}, slugs)
+
+	redirects := make(map[string]string)
+	for _, r := range result.Redirects {
+		redirects[r.From] = r.To
+	}
+	require.Equal(t, map[string]string{
+		`^/company/team/?$`:  "/team-2/",
+		`^/projects/team/?$`: "/team-3/",
+	}, redirects)
+}
@@ -0,0 +1,105 @@
+package wordpress
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// The WXR elements read by Convert. Elements are matched by local name, so
+// every WXR version is accepted whatever its namespace URLs.
+
+type wxr struct {
+	Channel wxrChannel `xml:"channel"`
+}
+
+type wxrChannel struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	Authors    []wxrAuthor   `xml:"author"`
+	Categories []wxrCategory `xml:"category"`
+	Tags       []wxrTag      `xml:"tag"`
+	Items      []wxrItem     `xml:"item"`
+}
+
+type wxrAuthor struct {
+	ID          string `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrCategory struct {
+	Slug        string `xml:"category_nicename"`
+	Name        string `xml:"cat_name"`
+	Description string `xml:"category_description"`
+}
+
+type wxrTag struct {
+	Slug        string `xml:"tag_slug"`
+	Name        string `xml:"tag_name"`
+	Description string `xml:"tag_description"`
+}
+
+type wxrItem struct {
+	Title         string        `xml:"title"`
+	Link          string        `xml:"link"`
+	Creator       string        `xml:"creator"`
+	Encoded       []wxrEncoded  `xml:"encoded"`
+	ID            string        `xml:"post_id"`
+	Date          string        `xml:"post_date"`
+	DateGMT       string        `xml:"post_date_gmt"`
+	ModifiedGMT   string        `xml:"post_modified_gmt"`
+	Name          string        `xml:"post_name"`
+	Status        string        `xml:"status"`
+	Type          string        `xml:"post_type"`
+	Password      string        `xml:"post_password"`
+	Sticky        string        `xml:"is_sticky"`
+	AttachmentURL string        `xml:"attachment_url"`
+	Terms         []wxrItemTerm `xml:"category"`
+	Meta          []wxrItemMeta `xml:"postmeta"`
+}
+
+// wxrEncoded is a content:encoded or excerpt:encoded element, which share
+// their local name.
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrItemTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrItemMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+func (item *wxrItem) content() string {
+	for _, e := range item.Encoded {
+		if !strings.Contains(e.XMLName.Space, "excerpt") {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+func (item *wxrItem) excerpt() string {
+	for _, e := range item.Encoded {
+		if strings.Contains(e.XMLName.Space, "excerpt") {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+func (item *wxrItem) meta(key string) string {
+	for _, m := range item.Meta {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return ""
+}